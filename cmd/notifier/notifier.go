@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/alexlast/stock-notifier/internal/notifier"
@@ -51,23 +54,106 @@ func main() {
 		},
 	)
 
+	// Register retailers defined in config
+	err = notifier.LoadRetailers(config.RetailerFile)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Build the notification cache
+	cache, err := notifier.NewCache(config)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Load the price history
+	history, err := notifier.NewPriceHistory(config.HistoryFile)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Load the product state
+	state, err := notifier.NewStateTracker(config.StateFile)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Route requests through any proxies
+	proxies, err := notifier.NewProxyPool(config)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Notifications never go through the proxies
+	direct := &http.Client{
+		Timeout: (time.Second * 10),
+	}
+
+	client := direct
+
+	if proxies != nil {
+		client = proxies.Client(time.Second * 10)
+	}
+
 	// Build new clients
 	c := &notifier.Context{
-		SES: ses.New(session),
-		SNS: sns.New(session),
-		HTTP: &http.Client{
-			Timeout: (time.Second * 10),
-		},
-		Config: config,
+		SES:      ses.New(session),
+		SNS:      sns.New(session),
+		HTTP:     client,
+		Direct:   direct,
+		Cache:    cache,
+		State:    state,
+		History:  history,
+		Limiter:  notifier.NewHostLimiter(config),
+		Breakers: notifier.NewBreakers(config),
+		Health:   notifier.NewParserHealth(config),
+		Status:   notifier.NewStatusTracker(),
+		Config:   config,
 	}
 
 	// Were ready to start
 	log.Infoln("Starting stock-notifier")
 
-	// Start polling
-	go c.Start()
+	// Stop polling on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Serve prometheus metrics
+	// Serve prometheus metrics and the status API
 	http.Handle("/metrics", promhttp.Handler())
-	http.ListenAndServe(":9125", nil)
+	http.Handle("/api/", c.StatusHandler())
+	server := &http.Server{Addr: ":9125"}
+
+	go func() {
+		err := server.ListenAndServe()
+
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalln(err)
+		}
+	}()
+
+	// Start polling until we're signalled
+	err = c.Start(ctx)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	log.Infoln("Shutting down stock-notifier")
+
+	// Give running polls time to finish
+	shutdown, cancel := context.WithTimeout(context.Background(), time.Duration(config.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	err = c.Shutdown(shutdown)
+
+	if err != nil {
+		log.Errorln(err)
+	}
+
+	server.Shutdown(shutdown)
 }
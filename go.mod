@@ -4,10 +4,13 @@ go 1.16
 
 require (
 	github.com/PuerkitoBio/goquery v1.6.1
+	github.com/alicebob/miniredis/v2 v2.14.3
 	github.com/aws/aws-sdk-go v1.37.19
-	github.com/jasonlvhit/gocron v0.0.1
+	github.com/gomodule/redigo v1.8.9
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/prometheus/client_golang v1.9.0
 	github.com/sirupsen/logrus v1.8.0
 	github.com/stretchr/testify v1.7.0
+	github.com/tidwall/gjson v1.14.4
+	gopkg.in/yaml.v2 v2.3.0
 )
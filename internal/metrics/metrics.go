@@ -28,6 +28,92 @@ var (
 			"retailer",
 		},
 	)
+	// SentNotifications is a counter for notifications
+	// successfully sent via a channel
+	SentNotifications = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stock_notifier_sent_notifications_total",
+			Help: "Number of notifications sent via a channel",
+		},
+		[]string{
+			"channel",
+		},
+	)
+	// FailedNotifications is a counter for notifications
+	// that failed to send via a channel
+	FailedNotifications = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stock_notifier_failed_notifications_total",
+			Help: "Number of notifications that failed to send via a channel",
+		},
+		[]string{
+			"channel",
+		},
+	)
+	// CacheEntries is a gauge for the number of
+	// entries in the notification cache
+	CacheEntries = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "stock_notifier_cache_entries",
+			Help: "Number of entries in the notification cache",
+		},
+	)
+	// CircuitBreakerState is a gauge for the circuit breaker
+	// state of a retailer, 0 closed, 1 half open and 2 open
+	CircuitBreakerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stock_notifier_circuit_breaker_state",
+			Help: "Circuit breaker state of a retailer, 0 closed, 1 half open and 2 open",
+		},
+		[]string{
+			"retailer",
+		},
+	)
+	// SkippedPolls is a counter for polls skipped because
+	// the previous poll was still in progress
+	SkippedPolls = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stock_notifier_skipped_polls_total",
+			Help: "Number of polls skipped because the previous poll was still in progress",
+		},
+		[]string{
+			"retailer",
+		},
+	)
+	// ProxyRequests is a counter for requests sent
+	// through a proxy by result
+	ProxyRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stock_notifier_proxy_requests_total",
+			Help: "Number of requests sent through a proxy by result",
+		},
+		[]string{
+			"proxy",
+			"result",
+		},
+	)
+	// ProxyQuarantined is a gauge for whether a
+	// proxy is quarantined, 1 when quarantined
+	ProxyQuarantined = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stock_notifier_proxy_quarantined",
+			Help: "Whether a proxy is quarantined, 1 when quarantined",
+		},
+		[]string{
+			"proxy",
+		},
+	)
+	// ParserDegraded is a gauge for whether the parser
+	// of a retailer is degraded, 1 when degraded
+	ParserDegraded = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stock_notifier_parser_degraded",
+			Help: "Whether the parser of a retailer is degraded, 1 when degraded",
+		},
+		[]string{
+			"retailer",
+		},
+	)
 	// ParsedProducts is a counter for products parsed
 	ParsedProducts = promauto.NewCounterVec(
 		prometheus.CounterOpts{
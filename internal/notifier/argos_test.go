@@ -1,29 +1,21 @@
 package notifier
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-// TestFetchArgos ensures the function is parsing
-// products correctly, the retailer is not mocked
+// TestFetchArgos ensures the function is parsing products
+// correctly from the recorded responses of the retailer
 func TestFetchArgos(t *testing.T) {
-	c := GetTestContext()
-
-	// Test filter that should return products
-	// we will actually scrape the retailer live
-	filter := Filter{
-		Term:     "Playstation 5",
-		MinPrice: 300,
-		MaxPrice: 600,
-	}
+	c := GetCassetteContext(t, "argos")
+	filter := Filter{Term: "Playstation 5"}
 
 	// Check the retailer
-	response, err := c.FetchArgos(filter, &[]Product{}, 1, 1)
-	response.Parsed = len(response.Matches)
-	response.Matches = FilterProducts(response.Matches, filter)
+	response, err := c.FetchArgos(context.Background(), filter)
 
 	assert.Nil(t, err)
-	assert.NotEqual(t, 0, response.Parsed)
+	assertRecorded(t, response, filter, "www.argos.co.uk")
 }
@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/sns"
+)
+
+// smsChannel sends alerts as an SMS via AWS SNS
+type smsChannel struct {
+	phone *string
+}
+
+// emailChannel sends alerts as an email via AWS SES
+type emailChannel struct {
+	email *string
+}
+
+func init() {
+	RegisterChannel("sms", func(n Notify) Channel {
+		if n.Phone == nil {
+			return nil
+		}
+
+		return &smsChannel{phone: n.Phone}
+	})
+
+	RegisterChannel("email", func(n Notify) Channel {
+		if n.Email == nil {
+			return nil
+		}
+
+		return &emailChannel{email: n.Email}
+	})
+}
+
+// BuildSNS returns the SNS publish input
+func BuildSNS(message string, phone *string) *sns.PublishInput {
+	return &sns.PublishInput{
+		Message:     aws.String(message),
+		PhoneNumber: phone,
+		MessageAttributes: map[string]*sns.MessageAttributeValue{
+			"AWS.SNS.SMS.SenderID": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(smsFromName),
+			},
+			"AWS.SNS.SMS.SMSType": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String("Transactional"),
+			},
+		},
+	}
+}
+
+// BuildSES returns the SES send email input
+func BuildSES(from, message string, email *string) *ses.SendEmailInput {
+	return &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			ToAddresses: []*string{email},
+		},
+		Message: &ses.Message{
+			Body: &ses.Body{
+				Text: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(message),
+				},
+			},
+			Subject: &ses.Content{
+				Charset: aws.String("UTF-8"),
+				Data:    aws.String("New alert from stock-notifier"),
+			},
+		},
+		Source: aws.String(from),
+	}
+}
+
+// Name returns the name of the channel
+func (s *smsChannel) Name() string {
+	return "sms"
+}
+
+// Send publishes the alert message via SNS
+func (s *smsChannel) Send(ctx context.Context, c *Context, alert Alert) error {
+	_, err := c.SNS.PublishWithContext(ctx, BuildSNS(alert.Message, s.phone))
+	return err
+}
+
+// Name returns the name of the channel
+func (e *emailChannel) Name() string {
+	return "email"
+}
+
+// Send emails the alert message via SES
+func (e *emailChannel) Send(ctx context.Context, c *Context, alert Alert) error {
+	_, err := c.SES.SendEmailWithContext(ctx, BuildSES(c.Config.FromAddress, alert.Message, e.email))
+	return err
+}
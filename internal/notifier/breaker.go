@@ -0,0 +1,164 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alexlast/stock-notifier/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed allows polling as normal
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen allows a single trial poll
+	BreakerHalfOpen
+	// BreakerOpen pauses polling until the cooldown passes
+	BreakerOpen
+)
+
+// breaker tracks the failures of a single retailer
+type breaker struct {
+	state    BreakerState
+	failures int
+	opened   time.Time
+}
+
+// Breakers is a set of per-retailer circuit breakers, a retailer
+// is paused for the cooldown after threshold consecutive failed
+// polls and then a single trial poll decides whether to resume
+type Breakers struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	breakers  map[string]*breaker
+	now       func() time.Time
+}
+
+// NewBreakers builds the circuit breakers from the
+// config, a zero threshold disables the breakers
+func NewBreakers(config *Config) *Breakers {
+	return &Breakers{
+		threshold: config.BreakerThreshold,
+		cooldown:  time.Duration(config.BreakerCooldown) * time.Second,
+		breakers:  map[string]*breaker{},
+		now:       time.Now,
+	}
+}
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+
+	return "closed"
+}
+
+// Allow checks whether the retailer can be polled, an open breaker
+// becomes half open once the cooldown has passed and lets one poll
+// through to test the retailer
+func (b *Breakers) Allow(retailer string) bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.get(retailer)
+
+	switch br.state {
+	case BreakerOpen:
+		if b.now().Sub(br.opened) < b.cooldown {
+			return false
+		}
+
+		b.setState(retailer, br, BreakerHalfOpen)
+
+		return true
+	case BreakerHalfOpen:
+		// A trial poll is already in flight
+		return false
+	}
+
+	return true
+}
+
+// Success records a successful poll closing the breaker
+func (b *Breakers) Success(retailer string) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.get(retailer)
+	br.failures = 0
+
+	if br.state != BreakerClosed {
+		log.Infof("Circuit breaker for %s closed, resuming polling", retailer)
+		b.setState(retailer, br, BreakerClosed)
+	}
+}
+
+// Failure records a failed poll opening the breaker when the
+// threshold is reached or the trial poll of a half open breaker fails
+func (b *Breakers) Failure(retailer string) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.get(retailer)
+	br.failures++
+
+	if br.state == BreakerHalfOpen || (br.state == BreakerClosed && br.failures >= b.threshold) {
+		log.Warnf("Circuit breaker for %s opened after %d failures, pausing polling for %s", retailer, br.failures, b.cooldown)
+
+		br.opened = b.now()
+		b.setState(retailer, br, BreakerOpen)
+	}
+}
+
+// State returns the state of the retailer breaker
+func (b *Breakers) State(retailer string) BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.get(retailer).state
+}
+
+// get returns the breaker for the retailer, the lock must be held
+func (b *Breakers) get(retailer string) *breaker {
+	br, ok := b.breakers[retailer]
+
+	if !ok {
+		br = &breaker{}
+		b.breakers[retailer] = br
+	}
+
+	return br
+}
+
+// setState updates the breaker state and metric
+func (b *Breakers) setState(retailer string, br *breaker, state BreakerState) {
+	br.state = state
+
+	metrics.CircuitBreakerState.With(
+		prometheus.Labels{"retailer": retailer}).Set(float64(state))
+}
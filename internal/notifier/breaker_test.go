@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBreakers tests the circuit breaker
+// opens, recovers and reopens
+func TestBreakers(t *testing.T) {
+	now := time.Now()
+
+	breakers := NewBreakers(&Config{BreakerThreshold: 2, BreakerCooldown: 60})
+	breakers.now = func() time.Time { return now }
+
+	// Failures below the threshold keep polling
+	breakers.Failure("test")
+	assert.True(t, breakers.Allow("test"))
+
+	// A success resets the failures
+	breakers.Success("test")
+	breakers.Failure("test")
+	assert.Equal(t, BreakerClosed, breakers.State("test"))
+
+	// Reaching the threshold pauses polling
+	breakers.Failure("test")
+	assert.Equal(t, BreakerOpen, breakers.State("test"))
+	assert.False(t, breakers.Allow("test"))
+	assert.True(t, breakers.Allow("other"))
+
+	// A single trial poll after the cooldown
+	now = now.Add(time.Minute)
+	assert.True(t, breakers.Allow("test"))
+	assert.Equal(t, BreakerHalfOpen, breakers.State("test"))
+	assert.False(t, breakers.Allow("test"))
+
+	// A failed trial reopens the breaker
+	breakers.Failure("test")
+	assert.Equal(t, BreakerOpen, breakers.State("test"))
+	assert.False(t, breakers.Allow("test"))
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	assert.True(t, breakers.Allow("test"))
+	breakers.Success("test")
+	assert.Equal(t, BreakerClosed, breakers.State("test"))
+	assert.Equal(t, "closed", breakers.State("test").String())
+}
+
+// TestPollRetailerBreaker ensures polling is
+// paused while the breaker is open
+func TestPollRetailerBreaker(t *testing.T) {
+	var fetches int
+
+	retailer := &retailerFunc{
+		name: "test-breaker",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			fetches++
+			return Response{}, errors.New("Some fetch error")
+		},
+	}
+
+	c := GetTestContext()
+	c.Config = &Config{}
+	c.Breakers = NewBreakers(&Config{BreakerThreshold: 2, BreakerCooldown: 60})
+
+	for i := 0; i < 5; i++ {
+		c.PollRetailer(context.Background(), retailer, Filter{Term: "test"})
+	}
+
+	assert.Equal(t, 2, fetches)
+	assert.Equal(t, BreakerOpen, c.Breakers.State("test-breaker"))
+}
@@ -0,0 +1,260 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alexlast/stock-notifier/internal/metrics"
+)
+
+const (
+	cacheBackendMemory = "memory"
+	cacheBackendFile   = "file"
+	cacheBackendRedis  = "redis"
+	cacheSweepInterval = 60
+)
+
+// Cache defines the interface for the
+// notification dedupe cache backends
+type Cache interface {
+	// Get returns when the key was last notified
+	Get(key string) (time.Time, bool, error)
+	// Set records when the key was notified, the entry
+	// may be discarded once the ttl has passed
+	Set(key string, sent time.Time, ttl time.Duration) error
+}
+
+// Sweeper is implemented by caches that need
+// expired entries removed periodically
+type Sweeper interface {
+	// Sweep removes all expired entries
+	Sweep()
+}
+
+// memoryEntry defines the structure
+// of an entry in the memory cache
+type memoryEntry struct {
+	sent    time.Time
+	expires time.Time
+}
+
+// memoryCache is a concurrency safe in memory
+// cache that is lost when the process exits
+type memoryCache struct {
+	sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+// fileCache is a cache persisted to a JSON
+// file so it survives restarts
+type fileCache struct {
+	sync.Mutex
+	path    string
+	entries map[string]time.Time
+}
+
+// NewCache returns the cache backend
+// selected by the config
+func NewCache(config *Config) (Cache, error) {
+	switch config.CacheBackend {
+	case cacheBackendMemory, "":
+		return NewMemoryCache(), nil
+	case cacheBackendFile:
+		return NewFileCache(config.CacheFile)
+	case cacheBackendRedis:
+		return NewRedisCache(config.RedisURL)
+	}
+
+	return nil, fmt.Errorf("Unknown cache backend %s", config.CacheBackend)
+}
+
+// NewMemoryCache returns a new in memory cache
+func NewMemoryCache() Cache {
+	return &memoryCache{
+		entries: map[string]memoryEntry{},
+	}
+}
+
+// Get returns when the key was last notified,
+// expired entries are treated as missing
+func (m *memoryCache) Get(key string) (time.Time, bool, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	entry, exists := m.entries[key]
+
+	if !exists || time.Now().After(entry.expires) {
+		return time.Time{}, false, nil
+	}
+
+	return entry.sent, true, nil
+}
+
+// Set records when the key was notified
+func (m *memoryCache) Set(key string, sent time.Time, ttl time.Duration) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries[key] = memoryEntry{
+		sent:    sent,
+		expires: sent.Add(ttl),
+	}
+
+	metrics.CacheEntries.Set(float64(len(m.entries)))
+
+	return nil
+}
+
+// Sweep removes all expired entries
+func (m *memoryCache) Sweep() {
+	m.Lock()
+	defer m.Unlock()
+
+	now := time.Now()
+
+	for key, entry := range m.entries {
+		if now.After(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+
+	metrics.CacheEntries.Set(float64(len(m.entries)))
+}
+
+// NewFileCache returns a new cache persisted to the
+// file at path, loading any existing entries
+func NewFileCache(path string) (Cache, error) {
+	f := &fileCache{
+		path:    path,
+		entries: map[string]time.Time{},
+	}
+
+	raw, err := ioutil.ReadFile(path)
+
+	// Nothing has been cached yet
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read cache file %s, error: %v", path, err)
+	}
+
+	err = json.Unmarshal(raw, &f.entries)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal cache file %s, error: %v", path, err)
+	}
+
+	return f, nil
+}
+
+// Get returns when the key was last notified
+func (f *fileCache) Get(key string) (time.Time, bool, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	sent, exists := f.entries[key]
+
+	return sent, exists, nil
+}
+
+// Set records when the key was notified, dropping any
+// expired entries before writing the file
+func (f *fileCache) Set(key string, sent time.Time, ttl time.Duration) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.entries[key] = sent
+
+	for k, v := range f.entries {
+		if time.Since(v) > ttl && k != key {
+			delete(f.entries, k)
+		}
+	}
+
+	metrics.CacheEntries.Set(float64(len(f.entries)))
+
+	return f.write()
+}
+
+// Close writes any entries to the file
+func (f *fileCache) Close() error {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.write()
+}
+
+// write atomically replaces the cache file
+// with the current entries
+func (f *fileCache) write() error {
+	raw, err := json.Marshal(f.entries)
+
+	if err != nil {
+		return fmt.Errorf("Unable to marshal cache, error: %v", err)
+	}
+
+	err = writeFileAtomic(f.path, raw)
+
+	if err != nil {
+		return fmt.Errorf("Unable to write cache file %s, error: %v", f.path, err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes to a temporary file and renames it over
+// path so a crash can't leave a partially written file
+func writeFileAtomic(path string, raw []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(raw)
+
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// sweepCache periodically removes expired entries from the
+// cache if the backend requires it until ctx is cancelled
+func (c *Context) sweepCache(ctx context.Context) {
+	sweeper, ok := c.Cache.(Sweeper)
+
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(cacheSweepInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sweeper.Sweep()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
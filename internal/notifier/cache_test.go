@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCache runs the common tests
+// against any cache backend
+func testCache(t *testing.T, cache Cache) {
+	_, exists, err := cache.Get("missing")
+
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	// Set and get a key
+	now := time.Now()
+	err = cache.Set("key", now, time.Minute)
+	assert.Nil(t, err)
+
+	sent, exists, err := cache.Get("key")
+
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.True(t, now.Equal(sent))
+}
+
+// TestMemoryCache tests the memory cache
+func TestMemoryCache(t *testing.T) {
+	testCache(t, NewMemoryCache())
+}
+
+// TestMemoryCacheExpiry ensures expired entries are
+// treated as missing and removed by the sweeper
+func TestMemoryCacheExpiry(t *testing.T) {
+	cache := NewMemoryCache().(*memoryCache)
+
+	cache.Set("expired", time.Now().Add(-time.Hour), time.Minute)
+	cache.Set("valid", time.Now(), time.Minute)
+
+	_, exists, _ := cache.Get("expired")
+	assert.False(t, exists)
+
+	// Sweep the expired entry
+	cache.Sweep()
+
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, "valid")
+}
+
+// TestMemoryCacheConcurrency ensures the memory cache
+// can be used from multiple polls at once
+func TestMemoryCacheConcurrency(t *testing.T) {
+	cache := NewMemoryCache().(*memoryCache)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			key := fmt.Sprintf("key-%d", i%10)
+			cache.Set(key, time.Now(), time.Minute)
+			cache.Get(key)
+			cache.Sweep()
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Len(t, cache.entries, 10)
+}
+
+// TestFileCache tests the file cache and
+// ensures entries survive a restart
+func TestFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "notifications.json")
+	cache, err := NewFileCache(path)
+	assert.Nil(t, err)
+
+	testCache(t, cache)
+
+	// Expired entries are dropped on write
+	err = cache.Set("expired", time.Now().Add(-time.Hour), time.Minute)
+	assert.Nil(t, err)
+	err = cache.Set("other", time.Now(), time.Minute)
+	assert.Nil(t, err)
+
+	// Load the cache again
+	cache, err = NewFileCache(path)
+	assert.Nil(t, err)
+
+	_, exists, _ := cache.Get("key")
+	assert.True(t, exists)
+
+	_, exists, _ = cache.Get("expired")
+	assert.False(t, exists)
+
+	// A corrupt file should error
+	err = ioutil.WriteFile(path, []byte("{"), 0644)
+	assert.Nil(t, err)
+
+	_, err = NewFileCache(path)
+	assert.NotNil(t, err)
+}
+
+// TestRedisCache tests the redis cache
+// against an in memory redis server
+func TestRedisCache(t *testing.T) {
+	server, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer server.Close()
+
+	cache, err := NewCache(&Config{CacheBackend: cacheBackendRedis, RedisURL: "redis://" + server.Addr()})
+	assert.Nil(t, err)
+
+	testCache(t, cache)
+
+	// Redis should expire the key
+	assert.True(t, server.Exists(redisKeyPrefix+"key"))
+	server.FastForward(time.Minute)
+	assert.False(t, server.Exists(redisKeyPrefix+"key"))
+}
+
+// TestNewCache tests selecting
+// the cache backend
+func TestNewCache(t *testing.T) {
+	cache, err := NewCache(&Config{})
+
+	assert.Nil(t, err)
+	assert.IsType(t, &memoryCache{}, cache)
+
+	_, err = NewCache(&Config{CacheBackend: "unknown"})
+	assert.NotNil(t, err)
+
+	// Unreachable redis should error
+	_, err = NewCache(&Config{CacheBackend: cacheBackendRedis, RedisURL: "redis://localhost:1"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,290 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var record = flag.Bool("record", false, "Record live retailer responses into the testdata cassettes")
+
+// interaction is a recorded request and its response
+type interaction struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// cassette is a round tripper replaying recorded interactions,
+// when recording requests are sent live and saved instead
+type cassette struct {
+	mu           sync.Mutex
+	path         string
+	recording    bool
+	live         http.RoundTripper
+	Interactions []*interaction `json:"interactions"`
+}
+
+// loadCassette loads the cassette at path, a new
+// cassette is started when recording
+func loadCassette(path string, recording bool) (*cassette, error) {
+	c := &cassette{
+		path:      path,
+		recording: recording,
+		live:      http.DefaultTransport,
+	}
+
+	if recording {
+		return c, nil
+	}
+
+	raw, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read cassette %s, error: %v", path, err)
+	}
+
+	err = json.Unmarshal(raw, c)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal cassette %s, error: %v", path, err)
+	}
+
+	return c, nil
+}
+
+// RoundTrip replays the response recorded for the request
+func (c *cassette) RoundTrip(request *http.Request) (*http.Response, error) {
+	if c.recording {
+		return c.recordTrip(request)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, i := range c.Interactions {
+		if i.Method == request.Method && sameURL(i.URL, request.URL) {
+			return i.response(request), nil
+		}
+	}
+
+	return nil, fmt.Errorf("No interaction recorded in %s for %s %s", c.path, request.Method, request.URL)
+}
+
+// recordTrip sends the request live and records the response
+func (c *cassette) recordTrip(request *http.Request) (*http.Response, error) {
+	response, err := c.live.RoundTrip(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	i := &interaction{
+		Method:  request.Method,
+		URL:     request.URL.String(),
+		Status:  response.StatusCode,
+		Headers: map[string]string{"Content-Type": response.Header.Get("Content-Type")},
+		Body:    string(body),
+	}
+
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, i)
+	c.mu.Unlock()
+
+	return i.response(request), nil
+}
+
+// save writes the recorded interactions to the cassette
+func (c *cassette) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	raw, err := json.MarshalIndent(c, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(c.path), 0755)
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path, append(raw, '\n'), 0644)
+}
+
+// response builds the recorded response for the request
+func (i *interaction) response(request *http.Request) *http.Response {
+	header := http.Header{}
+
+	for k, v := range i.Headers {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(i.Status),
+		StatusCode:    i.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(i.Body)),
+		ContentLength: int64(len(i.Body)),
+		Request:       request,
+	}
+}
+
+// sameURL compares a recorded URL with a request URL,
+// both are normalised before comparing
+func sameURL(recorded string, u *url.URL) bool {
+	r, err := url.Parse(recorded)
+
+	if err != nil {
+		return false
+	}
+
+	return r.String() == u.String()
+}
+
+// GetCassetteContext returns a test context replaying the named
+// cassette from testdata, with -record requests are sent live
+// and the cassette is rewritten when the test finishes, tests
+// are skipped until their cassette has been recorded
+func GetCassetteContext(t *testing.T, name string) *Context {
+	path := filepath.Join("testdata", "cassettes", name+".json")
+
+	if _, err := os.Stat(path); os.IsNotExist(err) && !*record {
+		t.Skipf("Cassette %s has not been recorded, run make record", path)
+	}
+
+	c, err := loadCassette(path, *record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *record {
+		t.Cleanup(func() {
+			err := c.save()
+
+			if err != nil {
+				t.Errorf("Unable to save cassette %s, error: %v", path, err)
+			}
+		})
+	}
+
+	ctx := GetTestContext()
+	ctx.HTTP = &http.Client{Transport: c}
+
+	return ctx
+}
+
+// assertRecorded checks the products parsed from a recording, the
+// listings change with every recording so rather than specific
+// products every product must be complete, link to the
+// retailer and some must match the search term
+func assertRecorded(t *testing.T, response Response, filter Filter, host string) {
+	assert.NotEmpty(t, response.Matches)
+	assert.NotEmpty(t, MatchProducts(response.Matches, filter))
+
+	problem, _ := NewParserHealth(&Config{ParserThreshold: 0.5}).Check(host, filter, response.Matches)
+	assert.Empty(t, problem)
+
+	for _, product := range response.Matches {
+		assert.True(t, strings.HasPrefix(product.URL, "https://"+host+"/"), product.URL)
+	}
+}
+
+// TestCassetteReplay ensures recorded interactions are
+// replayed and unknown requests are rejected
+func TestCassetteReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.json")
+	recorded := &cassette{path: path, Interactions: []*interaction{{
+		Method: http.MethodGet,
+		URL:    "https://test.shop/search?q=AMD+Ryzen",
+		Status: http.StatusOK,
+		Body:   "<div class=\"productColumns\"></div>",
+	}}}
+	assert.Nil(t, recorded.save())
+
+	player, err := loadCassette(path, false)
+	assert.Nil(t, err)
+
+	c := GetTestContext()
+	c.HTTP = &http.Client{Transport: player}
+
+	raw, err := c.getRaw(context.Background(), "https://test.shop/search?q=AMD+Ryzen")
+	assert.Nil(t, err)
+	assert.Contains(t, string(raw), "productColumns")
+
+	_, err = c.getRaw(context.Background(), "https://test.shop/search?q=RTX")
+	assert.NotNil(t, err)
+
+	_, err = loadCassette(filepath.Join(dir, "missing.json"), false)
+	assert.NotNil(t, err)
+}
+
+// TestCassetteRecord ensures live responses are
+// recorded and can then be replayed
+func TestCassetteRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"query": %q}`, r.URL.Query().Get("q"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "cassettes", "test.json")
+	recorder, err := loadCassette(path, true)
+	assert.Nil(t, err)
+
+	c := GetTestContext()
+	c.HTTP = &http.Client{Transport: recorder}
+
+	raw, err := c.getRaw(context.Background(), server.URL+"/search?q=rtx")
+	assert.Nil(t, err)
+	assert.Nil(t, recorder.save())
+
+	// Replay without the server
+	server.Close()
+
+	player, err := loadCassette(path, false)
+	assert.Nil(t, err)
+	c.HTTP = &http.Client{Transport: player}
+
+	replayed, err := c.getRaw(context.Background(), server.URL+"/search?q=rtx")
+	assert.Nil(t, err)
+	assert.Equal(t, raw, replayed)
+	assert.Equal(t, "application/json", player.Interactions[0].Headers["Content-Type"])
+}
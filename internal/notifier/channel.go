@@ -0,0 +1,172 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alexlast/stock-notifier/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// EventInStock is sent when products come into stock
+	EventInStock = "in_stock"
+	// EventSoldOut is sent when products sell out
+	EventSoldOut = "sold_out"
+	// EventPriceChange is sent when an in stock
+	// product changes price
+	EventPriceChange = "price_change"
+	// EventPriceDrop is sent when a product
+	// drops in price compared to its history
+	EventPriceDrop = "price_drop"
+	// EventParserDegraded is sent to admins when the
+	// parser of a retailer looks broken
+	EventParserDegraded = "parser_degraded"
+	// EventParserRecovered is sent to admins when
+	// a degraded parser works again
+	EventParserRecovered = "parser_recovered"
+)
+
+// Alert defines the structure of a
+// notification sent to every channel
+type Alert struct {
+	Event    string
+	Retailer string
+	Term     string
+	Products []Product
+	Message  string
+}
+
+// Channel defines the interface every
+// notification channel must implement
+type Channel interface {
+	// Name returns the name of the channel
+	Name() string
+	// Send delivers the alert via the channel
+	Send(ctx context.Context, c *Context, alert Alert) error
+}
+
+// ChannelFactory resolves a notify entry to a channel,
+// returning nil when the entry doesn't configure it
+type ChannelFactory func(n Notify) Channel
+
+// ChannelErrors is an aggregate of errors
+// returned by channels, keyed by channel name
+type ChannelErrors map[string]error
+
+// channels holds the factory for every
+// notification channel, keyed by name
+var channels = struct {
+	sync.RWMutex
+	factories map[string]ChannelFactory
+}{
+	factories: map[string]ChannelFactory{},
+}
+
+// Title returns a short headline for the alert
+func (a Alert) Title() string {
+	switch a.Event {
+	case EventSoldOut:
+		return fmt.Sprintf("Sold out on %s for %s", a.Retailer, a.Term)
+	case EventPriceChange:
+		return fmt.Sprintf("Price change on %s for %s", a.Retailer, a.Term)
+	case EventPriceDrop:
+		return fmt.Sprintf("Price drop on %s for %s", a.Retailer, a.Term)
+	case EventParserDegraded:
+		return fmt.Sprintf("Parser degraded on %s for %s", a.Retailer, a.Term)
+	case EventParserRecovered:
+		return fmt.Sprintf("Parser recovered on %s for %s", a.Retailer, a.Term)
+	}
+
+	return fmt.Sprintf("Stock found on %s for %s", a.Retailer, a.Term)
+}
+
+// RegisterChannel adds a channel factory to the registry, a
+// factory with the same name will be replaced
+func RegisterChannel(name string, factory ChannelFactory) {
+	channels.Lock()
+	defer channels.Unlock()
+
+	channels.factories[name] = factory
+}
+
+// Channels returns every channel configured
+// by the notify entry, sorted by name
+func (n Notify) Channels() []Channel {
+	channels.RLock()
+	defer channels.RUnlock()
+
+	var names []string
+
+	for name := range channels.factories {
+		names = append(names, name)
+	}
+
+	// Sort so send order is predictable
+	sort.Strings(names)
+
+	var resolved []Channel
+
+	for _, name := range names {
+		if channel := channels.factories[name](n); channel != nil {
+			resolved = append(resolved, channel)
+		}
+	}
+
+	return resolved
+}
+
+// Error returns every channel error
+// as a single message
+func (e ChannelErrors) Error() string {
+	var names []string
+
+	for name := range e {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var errs []string
+
+	for _, name := range names {
+		errs = append(errs, fmt.Sprintf("%s: %v", name, e[name]))
+	}
+
+	return strings.Join(errs, "; ")
+}
+
+// sendAlert sends the alert via every channel configured
+// by the notify entry, a failing channel won't prevent
+// the remaining channels from being sent
+func (c *Context) sendAlert(ctx context.Context, alert Alert, notify Notify) error {
+	errs := ChannelErrors{}
+
+	for _, channel := range notify.Channels() {
+		err := channel.Send(ctx, c, alert)
+		c.recordNotification(channel.Name(), alert, err)
+
+		if err != nil {
+			errs[channel.Name()] = err
+
+			// Increment the failed counter
+			metrics.FailedNotifications.With(
+				prometheus.Labels{"channel": channel.Name()}).Inc()
+
+			continue
+		}
+
+		// Increment the sent counter
+		metrics.SentNotifications.With(
+			prometheus.Labels{"channel": channel.Name()}).Inc()
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNotifyChannels ensures notify entries
+// resolve to their configured channels
+func TestNotifyChannels(t *testing.T) {
+	notify := Notify{
+		Email:   aws.String("test@example.com"),
+		Phone:   aws.String("+12345678"),
+		Discord: aws.String("https://example.com/discord"),
+	}
+
+	var names []string
+
+	for _, channel := range notify.Channels() {
+		names = append(names, channel.Name())
+	}
+
+	assert.Equal(t, []string{"discord", "email", "sms"}, names)
+	assert.Empty(t, Notify{}.Channels())
+}
+
+// TestSendAlertErrors ensures a failing channel doesn't
+// mask the others and all errors are aggregated
+func TestSendAlertErrors(t *testing.T) {
+	c := GetTestContext()
+	c.Config = &Config{
+		FromAddress: "test@example.com",
+	}
+
+	sns := &mockSNSClient{
+		PublishReturnError: errors.New("SNS error"),
+	}
+
+	c.SNS = sns
+	c.SES = &mockSESClient{
+		SendEmailReturnError: errors.New("SES error"),
+	}
+
+	err := c.sendAlert(context.Background(), Alert{Message: "test"}, Notify{
+		Email: aws.String("test@example.com"),
+		Phone: aws.String("+12345678"),
+	})
+
+	// Both channels should have been attempted
+	assert.NotNil(t, sns.PublishInput)
+	assert.IsType(t, ChannelErrors{}, err)
+	assert.Len(t, err.(ChannelErrors), 2)
+	assert.Equal(t, "email: SES error; sms: SNS error", err.Error())
+}
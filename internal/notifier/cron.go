@@ -0,0 +1,298 @@
+package notifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cronSearchYears = 5
+)
+
+// Schedule returns the next time a job
+// should run after the given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule runs every interval
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// cronSchedule runs at the times matched by a cron expression,
+// each field is a bitset of the values it matches
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// Whether the day fields were restricted, when
+	// both are either of them may match
+	domRestricted, dowRestricted bool
+}
+
+// cronField defines the bounds of a cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+// ActiveHours defines the daily window polling is allowed
+// in, windows ending before they start span midnight
+type ActiveHours struct {
+	start, end time.Duration
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// Next returns the time one interval after t
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// ParseSchedule parses a standard five field cron expression such as
+// `*/5 7-23 * * 1-5`, a descriptor such as `@hourly` or `@every 90s`
+func ParseSchedule(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+
+	if strings.HasPrefix(expression, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expression, "@every ")))
+
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("Invalid interval in %s", expression)
+		}
+
+		return intervalSchedule{interval: interval}, nil
+	}
+
+	if descriptor, ok := cronDescriptors[expression]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("Expected %d fields in %s", len(cronFields), expression)
+	}
+
+	var bits [5]uint64
+
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		bits[i] = b
+	}
+
+	// Sunday can be either 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of
+// values, ranges and steps into a bitset
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		start, end, step := bounds.min, bounds.max, 1
+		rangePart := part
+
+		// Parse any step
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("Invalid step in %s field %s", bounds.name, part)
+			}
+
+			step = s
+			rangePart = part[:i]
+		}
+
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			ends := strings.SplitN(rangePart, "-", 2)
+
+			s, err1 := strconv.Atoi(ends[0])
+			e, err2 := strconv.Atoi(ends[1])
+
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("Invalid range in %s field %s", bounds.name, part)
+			}
+
+			start, end = s, e
+		default:
+			v, err := strconv.Atoi(rangePart)
+
+			if err != nil {
+				return 0, fmt.Errorf("Invalid value in %s field %s", bounds.name, part)
+			}
+
+			start = v
+
+			// A single value with a step runs to the max
+			if step == 1 {
+				end = v
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, fmt.Errorf("Out of range %s field %s, must be between %d and %d", bounds.name, part, bounds.min, bounds.max)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first matching minute after t
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches checks the day of month and day of week, when both are
+// restricted a match on either is enough like standard cron
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// HoursDecoder is a type used for an envconfig
+// custom decoder, it holds an active hours window
+type HoursDecoder string
+
+// Decode is a custom decoder validating the window
+func (h *HoursDecoder) Decode(value string) error {
+	_, err := ParseActiveHours(value)
+
+	if err != nil {
+		return fmt.Errorf("Invalid active hours, error: %v", err)
+	}
+
+	*h = HoursDecoder(value)
+
+	return nil
+}
+
+// ParseActiveHours parses a window such as `07:00-23:00`,
+// an empty window allows polling at any time
+func ParseActiveHours(window string) (*ActiveHours, error) {
+	if window == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(window, "-")
+
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Expected HH:MM-HH:MM, got %s", window)
+	}
+
+	var bounds [2]time.Duration
+
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+
+		if err != nil {
+			return nil, fmt.Errorf("Expected HH:MM-HH:MM, got %s", window)
+		}
+
+		bounds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	if bounds[0] == bounds[1] {
+		return nil, fmt.Errorf("The window %s is empty", window)
+	}
+
+	return &ActiveHours{start: bounds[0], end: bounds[1]}, nil
+}
+
+// Contains checks whether t is inside the window
+func (a *ActiveHours) Contains(t time.Time) bool {
+	if a == nil {
+		return true
+	}
+
+	offset := sinceMidnight(t)
+
+	// The window spans midnight
+	if a.end < a.start {
+		return offset >= a.start || offset < a.end
+	}
+
+	return offset >= a.start && offset < a.end
+}
+
+// NextStart returns the next time the window opens after t
+func (a *ActiveHours) NextStart(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	start := midnight.Add(a.start)
+
+	if !start.After(t) {
+		start = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()).Add(a.start)
+	}
+
+	return start
+}
+
+// sinceMidnight returns the time of day of t
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
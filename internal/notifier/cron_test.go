@@ -0,0 +1,80 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// at returns a local time on the given day for tests
+func at(day, hour, minute int) time.Time {
+	// 2021-03-01 is a Monday
+	return time.Date(2021, 3, day, hour, minute, 0, 0, time.Local)
+}
+
+// TestParseSchedule tests cron expressions
+// and descriptors are parsed and scheduled
+func TestParseSchedule(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		from       time.Time
+		next       time.Time
+	}{
+		{"*/15 * * * *", at(1, 10, 7), at(1, 10, 15)},
+		{"*/15 * * * *", at(1, 10, 45), at(1, 11, 0)},
+		{"0,30 9 * * *", at(1, 9, 0), at(1, 9, 30)},
+		{"0 7-23 * * 1-5", at(5, 23, 30), at(8, 7, 0)},
+		{"0 12 * * 7", at(1, 12, 0), at(7, 12, 0)},
+		{"0 0 13 * 5", at(1, 0, 0), at(5, 0, 0)},
+		{"@hourly", at(1, 10, 7), at(1, 11, 0)},
+		{"@daily", at(1, 10, 7), at(2, 0, 0)},
+		{"@every 90s", at(1, 10, 7), at(1, 10, 7).Add(90 * time.Second)},
+	} {
+		schedule, err := ParseSchedule(test.expression)
+
+		assert.Nil(t, err, test.expression)
+		assert.Equal(t, test.next, schedule.Next(test.from), test.expression)
+	}
+
+	// Impossible schedules never run
+	schedule, err := ParseSchedule("0 0 31 2 *")
+	assert.Nil(t, err)
+	assert.True(t, schedule.Next(at(1, 0, 0)).IsZero())
+
+	for _, expression := range []string{"61 * * * *", "* * *", "*/0 * * * *", "a * * * *", "5-1 * * * *", "@every soon", "@every -1s"} {
+		_, err := ParseSchedule(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+// TestActiveHours tests windows during
+// the day and spanning midnight
+func TestActiveHours(t *testing.T) {
+	day, err := ParseActiveHours("07:00-23:00")
+	assert.Nil(t, err)
+
+	assert.True(t, day.Contains(at(1, 12, 0)))
+	assert.True(t, day.Contains(at(1, 7, 0)))
+	assert.False(t, day.Contains(at(1, 6, 59)))
+	assert.False(t, day.Contains(at(1, 23, 0)))
+	assert.Equal(t, at(2, 7, 0), day.NextStart(at(1, 23, 30)))
+	assert.Equal(t, at(1, 7, 0), day.NextStart(at(1, 3, 0)))
+
+	night, err := ParseActiveHours("22:00-06:00")
+	assert.Nil(t, err)
+
+	assert.True(t, night.Contains(at(1, 23, 0)))
+	assert.True(t, night.Contains(at(1, 5, 0)))
+	assert.False(t, night.Contains(at(1, 12, 0)))
+
+	// No window is always active
+	none, err := ParseActiveHours("")
+	assert.Nil(t, err)
+	assert.True(t, none.Contains(at(1, 3, 0)))
+
+	for _, window := range []string{"7-23", "07:00", "10:00-10:00", "25:00-26:00"} {
+		_, err := ParseActiveHours(window)
+		assert.NotNil(t, err, window)
+	}
+}
@@ -0,0 +1,407 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	stockMatchExact     = "exact"
+	stockMatchContains  = "contains"
+	stockMatchRegex     = "regex"
+	defaultPricePattern = "[0-9].+[0-9]"
+)
+
+// RetailerDefinition describes how to search a retailer and
+// parse its results, the search URL is a template where
+// {term} and {page} are replaced for each request. Results
+// are parsed as HTML unless a JSON definition is given
+type RetailerDefinition struct {
+	Name       string           `json:"name" yaml:"name"`
+	Search     string           `json:"search" yaml:"search"`
+	JSON       *JSONDefinition  `json:"json" yaml:"json"`
+	Pagination *FieldDefinition `json:"pagination" yaml:"pagination"`
+	Products   string           `json:"products" yaml:"products"`
+	Title      FieldDefinition  `json:"title" yaml:"title"`
+	URL        FieldDefinition  `json:"url" yaml:"url"`
+	SKU        FieldDefinition  `json:"sku" yaml:"sku"`
+	Image      FieldDefinition  `json:"image" yaml:"image"`
+	Price      FieldDefinition  `json:"price" yaml:"price"`
+	Stock      StockDefinition  `json:"stock" yaml:"stock"`
+}
+
+// FieldDefinition extracts a value from the elements matching the
+// selector, or the product itself without one. The text is used
+// unless an attribute is given, any characters in remove are
+// stripped and a pattern keeps its first submatch or match
+type FieldDefinition struct {
+	Selector string `json:"selector" yaml:"selector"`
+	Attr     string `json:"attr" yaml:"attr"`
+	Pattern  string `json:"pattern" yaml:"pattern"`
+	Remove   string `json:"remove" yaml:"remove"`
+}
+
+// StockDefinition marks a product in stock when the
+// field matches the text, exactly by default
+type StockDefinition struct {
+	FieldDefinition `yaml:",inline"`
+	Text            string `json:"text" yaml:"text"`
+	Match           string `json:"match" yaml:"match"`
+}
+
+// field is a field definition with its pattern compiled
+type field struct {
+	FieldDefinition
+	pattern *regexp.Regexp
+}
+
+// HTMLRetailer is a retailer parsed with the
+// CSS selectors of its definition
+type HTMLRetailer struct {
+	definition RetailerDefinition
+	pagination *field
+	title      *field
+	url        *field
+	sku        *field
+	image      *field
+	price      *field
+	stock      *field
+	stockText  *regexp.Regexp
+}
+
+// NewHTMLRetailer validates the definition and compiles
+// its patterns into a retailer
+func NewHTMLRetailer(definition RetailerDefinition) (*HTMLRetailer, error) {
+	err := validateDefinition(definition)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if definition.Products == "" || definition.Title.Selector == "" {
+		return nil, fmt.Errorf("Retailer %s requires products and title selectors", definition.Name)
+	}
+
+	if definition.Pagination != nil && definition.Pagination.Selector == "" {
+		return nil, fmt.Errorf("Retailer %s requires a pagination selector", definition.Name)
+	}
+
+	// Prices are extracted with the same pattern as the built in retailers
+	if definition.Price.Pattern == "" {
+		definition.Price.Pattern = defaultPricePattern
+	}
+
+	r := &HTMLRetailer{definition: definition}
+
+	fields := []struct {
+		target     **field
+		definition *FieldDefinition
+	}{
+		{&r.pagination, definition.Pagination},
+		{&r.title, &definition.Title},
+		{&r.url, &definition.URL},
+		{&r.sku, &definition.SKU},
+		{&r.image, &definition.Image},
+		{&r.price, &definition.Price},
+		{&r.stock, &definition.Stock.FieldDefinition},
+	}
+
+	for _, f := range fields {
+		if f.definition == nil {
+			continue
+		}
+
+		compiled, err := compileField(*f.definition)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid field in retailer %s, error: %v", definition.Name, err)
+		}
+
+		*f.target = compiled
+	}
+
+	// Build the stock matcher
+	text := definition.Stock.Text
+
+	switch definition.Stock.Match {
+	case "", stockMatchExact:
+		text = "^" + regexp.QuoteMeta(text) + "$"
+	case stockMatchContains:
+		text = regexp.QuoteMeta(text)
+	case stockMatchRegex:
+	default:
+		return nil, fmt.Errorf("Unknown stock match %s for retailer %s", definition.Stock.Match, definition.Name)
+	}
+
+	stockText, err := regexp.Compile(text)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid stock text for retailer %s, error: %v", definition.Name, err)
+	}
+
+	r.stockText = stockText
+
+	return r, nil
+}
+
+// validateDefinition checks the fields
+// shared by every retailer definition
+func validateDefinition(definition RetailerDefinition) error {
+	if definition.Name == "" {
+		return fmt.Errorf("Retailer definitions require a name")
+	}
+
+	if !strings.Contains(definition.Search, "{term}") {
+		return fmt.Errorf("Retailer %s requires a search URL containing {term}", definition.Name)
+	}
+
+	return nil
+}
+
+// mustHTMLRetailer builds a built in retailer,
+// panicking on an invalid definition
+func mustHTMLRetailer(definition RetailerDefinition) *HTMLRetailer {
+	r, err := NewHTMLRetailer(definition)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// compileField compiles the pattern of a field definition
+func compileField(definition FieldDefinition) (*field, error) {
+	f := &field{FieldDefinition: definition}
+
+	if definition.Pattern != "" {
+		pattern, err := regexp.Compile(definition.Pattern)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.pattern = pattern
+	}
+
+	return f, nil
+}
+
+// LoadRetailers registers the retailers defined in a YAML
+// or JSON file, replacing any retailer with the same name
+func LoadRetailers(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("Unable to read retailer definitions, error: %v", err)
+	}
+
+	retailers, err := ParseRetailers(data, filepath.Ext(path) == ".json")
+
+	if err != nil {
+		return err
+	}
+
+	for _, r := range retailers {
+		if _, ok := GetRetailer(r.Name()); ok {
+			log.Infof("Replacing retailer %s with its definition", r.Name())
+		}
+
+		RegisterRetailer(r)
+	}
+
+	return nil
+}
+
+// ParseRetailers parses a list of retailer
+// definitions from YAML or JSON
+func ParseRetailers(data []byte, isJSON bool) ([]Retailer, error) {
+	var definitions []RetailerDefinition
+	var err error
+
+	if isJSON {
+		err = json.Unmarshal(data, &definitions)
+	} else {
+		err = yaml.UnmarshalStrict(data, &definitions)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse retailer definitions, error: %v", err)
+	}
+
+	var retailers []Retailer
+
+	for _, definition := range definitions {
+		var r Retailer
+		var err error
+
+		if definition.JSON != nil {
+			r, err = NewJSONRetailer(definition)
+		} else {
+			r, err = NewHTMLRetailer(definition)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		retailers = append(retailers, r)
+	}
+
+	return retailers, nil
+}
+
+// Name returns the display name of the retailer
+func (r *HTMLRetailer) Name() string {
+	return r.definition.Name
+}
+
+// Fetch returns all products found by the retailer for the filter
+func (r *HTMLRetailer) Fetch(ctx context.Context, c *Context, filter Filter) (Response, error) {
+	return c.paginate(ctx, func(ctx context.Context, page int) ([]Product, int, error) {
+		return r.fetchPage(ctx, c, filter, page)
+	})
+}
+
+// searchURL fills the search URL template
+// with the escaped term and page
+func searchURL(template string, term string, page int) string {
+	return strings.NewReplacer(
+		"{term}", url.QueryEscape(term),
+		"{page}", strconv.Itoa(page),
+	).Replace(template)
+}
+
+// fetchPage fetches and parses a single page of results
+func (r *HTMLRetailer) fetchPage(ctx context.Context, c *Context, filter Filter, cPage int) ([]Product, int, error) {
+	pageURL := searchURL(r.definition.Search, filter.Term, cPage)
+	page, err := c.getPage(ctx, pageURL)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches, fPage := r.parse(pageURL, page)
+
+	return matches, fPage, nil
+}
+
+// parse extracts the products and final
+// page number from a page of results
+func (r *HTMLRetailer) parse(pageURL string, page *goquery.Document) ([]Product, int) {
+	var matches []Product
+
+	fPage := 1
+
+	// Determine how many pages we need to parse,
+	// the last page number found wins
+	if r.pagination != nil {
+		page.Find(r.pagination.Selector).Each(func(i int, data *goquery.Selection) {
+			f, err := strconv.Atoi(r.pagination.value(data))
+
+			if err == nil {
+				fPage = f
+			}
+		})
+	}
+
+	// Get products on the current page and extract
+	// the fields we want to filter on
+	page.Find(r.definition.Products).Each(func(i int, data *goquery.Selection) {
+		product := Product{
+			Name: r.title.extract(data),
+			URL:  resolveURL(pageURL, r.url.extract(data)),
+			SKU:  r.sku.extract(data),
+		}
+
+		// Without an attribute the first image is used
+		if r.image.Attr == "" {
+			product.Image = imageSource(pageURL, r.image.find(data))
+		} else {
+			product.Image = resolveURL(pageURL, r.image.extract(data))
+		}
+
+		// Convert price to float
+		price := strings.ReplaceAll(r.price.extract(data), ",", "")
+		f, err := strconv.ParseFloat(price, 64)
+
+		if err == nil {
+			product.Price = f
+		}
+
+		// Ensure the product is in-stock
+		if r.stock.Selector != "" || r.stock.Attr != "" {
+			product.InStock = r.stockText.MatchString(r.stock.extract(data))
+		}
+
+		matches = append(matches, product)
+	})
+
+	return matches, fPage
+}
+
+// find returns the elements the field is extracted from
+func (f *field) find(s *goquery.Selection) *goquery.Selection {
+	if f.Selector == "" {
+		return s
+	}
+
+	return s.Find(f.Selector)
+}
+
+// extract returns the value of the field within
+// the product, empty fields are never matched
+func (f *field) extract(s *goquery.Selection) string {
+	if f.Selector == "" && f.Attr == "" {
+		return ""
+	}
+
+	return f.value(f.find(s))
+}
+
+// value returns the cleaned value of the selection
+func (f *field) value(s *goquery.Selection) string {
+	var value string
+
+	if f.Attr != "" {
+		value = s.AttrOr(f.Attr, "")
+	} else {
+		value = s.Text()
+	}
+
+	for _, r := range f.Remove {
+		value = strings.ReplaceAll(value, string(r), "")
+	}
+
+	value = strings.TrimSpace(value)
+
+	if f.pattern == nil {
+		return value
+	}
+
+	match := f.pattern.FindStringSubmatch(value)
+
+	switch {
+	case len(match) > 1:
+		return match[1]
+	case len(match) == 1:
+		return match[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,235 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDefinitions = `
+- name: Test.shop
+  search: "%s/search?q={term}&page={page}"
+  pagination:
+    selector: ul.pages li
+  products: div.product
+  title:
+    selector: h2
+    remove: "\n"
+  url:
+    selector: h2 a
+    attr: href
+  sku:
+    attr: data-sku
+  image:
+    selector: div.image
+  price:
+    selector: span.price
+  stock:
+    selector: span.stock
+    text: in stock
+    match: contains
+`
+
+const testResultsPage = `<html><body>
+<ul class="pages"><li>1</li><li>2</li><li>Next</li></ul>
+<div class="product" data-sku="A%[1]s">
+	<h2><a href="/product/a%[1]s">RTX 3070
+	Founders Edition</a></h2>
+	<div class="image"><img data-src="/images/a%[1]s.jpg"></div>
+	<span class="price">£1,499.99</span>
+	<span class="stock">Currently in stock</span>
+</div>
+<div class="product" data-sku="B%[1]s">
+	<h2><a href="/product/b%[1]s">RTX 3080</a></h2>
+	<span class="price">£649.00</span>
+	<span class="stock">Out of stock</span>
+</div>
+</body></html>`
+
+// TestHTMLRetailerFetch ensures defined retailers
+// are fetched and parsed with their selectors
+func TestHTMLRetailerFetch(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+
+		fmt.Fprintf(w, testResultsPage, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+
+	retailers, err := ParseRetailers([]byte(fmt.Sprintf(testDefinitions, server.URL)), false)
+	assert.Nil(t, err)
+	assert.Len(t, retailers, 1)
+
+	c := GetTestContext()
+	c.HTTP = server.Client()
+	c.Config = &Config{PageWorkers: 1}
+
+	response, err := retailers[0].Fetch(context.Background(), c, Filter{Term: "rtx 30"})
+
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"q=rtx+30&page=1", "q=rtx+30&page=2"}, queries)
+	assert.Len(t, response.Matches, 4)
+
+	for _, product := range response.Matches {
+		if product.SKU != "A1" {
+			continue
+		}
+
+		assert.Equal(t, "RTX 3070\tFounders Edition", product.Name)
+		assert.Equal(t, server.URL+"/product/a1", product.URL)
+		assert.Equal(t, server.URL+"/images/a1.jpg", product.Image)
+		assert.Equal(t, 1499.99, product.Price)
+		assert.True(t, product.InStock)
+	}
+}
+
+// TestHTMLRetailerStock ensures each
+// stock match mode is applied
+func TestHTMLRetailerStock(t *testing.T) {
+	page, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<div class="product"><h2>Test</h2><button>Add to Basket</button></div>`))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		text    string
+		match   string
+		inStock bool
+	}{
+		{"Add to Basket", "", true},
+		{"Add", stockMatchExact, false},
+		{"to Bask", stockMatchContains, true},
+		{"^Add to (Basket|Cart)$", stockMatchRegex, true},
+		{"Pre-order", stockMatchContains, false},
+	}
+
+	for _, test := range tests {
+		r, err := NewHTMLRetailer(RetailerDefinition{
+			Name:     "Test.shop",
+			Search:   "https://test.shop/search?q={term}",
+			Products: "div.product",
+			Title:    FieldDefinition{Selector: "h2"},
+			Stock:    StockDefinition{FieldDefinition: FieldDefinition{Selector: "button"}, Text: test.text, Match: test.match},
+		})
+		assert.Nil(t, err)
+
+		products, last := r.parse("https://test.shop/search", page)
+		assert.Equal(t, 1, last)
+		assert.Equal(t, test.inStock, products[0].InStock, test.text)
+	}
+}
+
+// TestHTMLRetailerBuiltIn ensures the selectors
+// of the built in retailers are valid
+func TestHTMLRetailerBuiltIn(t *testing.T) {
+	page, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<article class="product" data-sku="123">
+			<a href="/gbuk/rtx-3070-123-pdt.html"><span data-product="name"> RTX 3070 </span></a>
+			<strong class="price">£529.00</strong>
+			<li data-availability="homeDeliveryAvailable">FREE delivery available</li>
+		</article>`))
+	assert.Nil(t, err)
+
+	products, _ := currys.parse("https://www.currys.co.uk/gbuk/search", page)
+
+	assert.Equal(t, []Product{{
+		Name:    "RTX 3070",
+		URL:     "https://www.currys.co.uk/gbuk/rtx-3070-123-pdt.html",
+		SKU:     "123",
+		Price:   529,
+		InStock: true,
+	}}, products)
+
+	page, err = goquery.NewDocumentFromReader(strings.NewReader(`
+		<div class="results">Showing 1 of 3 Pages</div>
+		<div class="search-box-results">
+			<div class="search-box-title"><a href="/products/gpu/rtx3070.html">RTX 3070</a></div>
+		</div>`))
+	assert.Nil(t, err)
+
+	products, last := novatech.parse("https://www.novatech.co.uk/search.html", page)
+
+	assert.Equal(t, 3, last)
+	assert.Equal(t, "rtx3070", products[0].SKU)
+}
+
+// TestNewHTMLRetailerInvalid ensures invalid
+// definitions are rejected
+func TestNewHTMLRetailerInvalid(t *testing.T) {
+	valid := RetailerDefinition{
+		Name:     "Test.shop",
+		Search:   "https://test.shop/search?q={term}",
+		Products: "div.product",
+		Title:    FieldDefinition{Selector: "h2"},
+	}
+
+	_, err := NewHTMLRetailer(valid)
+	assert.Nil(t, err)
+
+	tests := []func(d *RetailerDefinition){
+		func(d *RetailerDefinition) { d.Name = "" },
+		func(d *RetailerDefinition) { d.Search = "https://test.shop/search" },
+		func(d *RetailerDefinition) { d.Products = "" },
+		func(d *RetailerDefinition) { d.Title.Selector = "" },
+		func(d *RetailerDefinition) { d.Pagination = &FieldDefinition{} },
+		func(d *RetailerDefinition) { d.Price.Pattern = "[0-9" },
+		func(d *RetailerDefinition) { d.Stock.Match = "fuzzy" },
+		func(d *RetailerDefinition) { d.Stock.Text, d.Stock.Match = "(", stockMatchRegex },
+	}
+
+	for _, modify := range tests {
+		definition := valid
+		modify(&definition)
+
+		_, err := NewHTMLRetailer(definition)
+		assert.NotNil(t, err)
+	}
+}
+
+// TestLoadRetailers ensures definitions are loaded from
+// YAML and JSON files and replace existing retailers
+func TestLoadRetailers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retailers")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, LoadRetailers(""))
+
+	// Unknown fields are rejected
+	path := filepath.Join(dir, "retailers.yaml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("- name: Test.shop\n  produtcs: div\n"), 0644))
+	assert.NotNil(t, LoadRetailers(path))
+
+	path = filepath.Join(dir, "retailers.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`[{
+		"name": "Ebuyer.com",
+		"search": "https://www.ebuyer.com/search?q={term}",
+		"products": "div.product",
+		"title": {"selector": "h3"},
+		"stock": {"selector": "button", "text": "Buy"}
+	}]`), 0644))
+
+	builtIn, _ := GetRetailer("Ebuyer.com")
+	defer RegisterRetailer(builtIn)
+
+	assert.Nil(t, LoadRetailers(path))
+
+	r, ok := GetRetailer("ebuyer.com")
+	assert.True(t, ok)
+	assert.NotEqual(t, builtIn, r)
+	assert.Equal(t, "button", r.(*HTMLRetailer).stock.Selector)
+}
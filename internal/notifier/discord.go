@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	discordUsername  = "stock-notifier"
+	discordColour    = 0x2ecc71
+	discordMaxEmbeds = 10
+)
+
+// discordChannel sends alerts to a Discord webhook
+type discordChannel struct {
+	url string
+}
+
+func init() {
+	RegisterChannel("discord", func(n Notify) Channel {
+		if n.Discord == nil {
+			return nil
+		}
+
+		return &discordChannel{url: *n.Discord}
+	})
+}
+
+// discordField defines the structure
+// of a field within an embed
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordImage defines the structure
+// of an image within an embed
+type discordImage struct {
+	URL string `json:"url"`
+}
+
+// discordEmbed defines the structure
+// of a Discord embed
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	URL       string         `json:"url,omitempty"`
+	Colour    int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Thumbnail *discordImage  `json:"thumbnail,omitempty"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// DiscordMessage defines the structure of
+// the body sent to a Discord webhook
+type DiscordMessage struct {
+	Username string         `json:"username"`
+	Content  string         `json:"content"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// BuildDiscord returns the Discord webhook messages, Discord
+// limits the number of embeds per message so products
+// are split over multiple messages when required
+func BuildDiscord(alert Alert) []*DiscordMessage {
+	var messages []*DiscordMessage
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	// Alerts without products are sent as text
+	if len(alert.Products) == 0 && alert.Message != "" {
+		return []*DiscordMessage{{
+			Username: discordUsername,
+			Content:  alert.Title() + "\n" + alert.Message,
+		}}
+	}
+
+	for i, product := range alert.Products {
+		// Start a new message when full
+		if i%discordMaxEmbeds == 0 {
+			messages = append(messages, &DiscordMessage{
+				Username: discordUsername,
+				Content:  alert.Title(),
+			})
+		}
+
+		embed := discordEmbed{
+			Title:  product.Name,
+			URL:    product.URL,
+			Colour: discordColour,
+			Fields: []discordField{
+				{Name: "Price", Value: fmt.Sprintf(priceFormat, product.Price), Inline: true},
+				{Name: "Retailer", Value: alert.Retailer, Inline: true},
+				{Name: "Filter", Value: alert.Term, Inline: true},
+			},
+			Timestamp: timestamp,
+		}
+
+		if product.Image != "" {
+			embed.Thumbnail = &discordImage{URL: product.Image}
+		}
+
+		message := messages[len(messages)-1]
+		message.Embeds = append(message.Embeds, embed)
+	}
+
+	return messages
+}
+
+// SendDiscord will send the messages to a Discord webhook
+func (c *Context) SendDiscord(ctx context.Context, url string, messages []*DiscordMessage) error {
+	for _, message := range messages {
+		body, err := json.Marshal(message)
+
+		if err != nil {
+			return fmt.Errorf("Unable to marshal Discord message, error: %v", err)
+		}
+
+		err = c.postJSON(ctx, url, body, nil)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Name returns the name of the channel
+func (d *discordChannel) Name() string {
+	return "discord"
+}
+
+// Send posts the alert as Discord embeds
+func (d *discordChannel) Send(ctx context.Context, c *Context, alert Alert) error {
+	return c.SendDiscord(ctx, d.url, BuildDiscord(alert))
+}
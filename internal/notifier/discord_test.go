@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildDiscord ensures products are
+// split into messages of embeds
+func TestBuildDiscord(t *testing.T) {
+	var products []Product
+
+	for i := 0; i < discordMaxEmbeds+1; i++ {
+		products = append(products, Product{Name: "RTX 3070", Price: 499.99, URL: "https://example.com/rtx", Image: "https://example.com/rtx.jpg"})
+	}
+
+	messages := BuildDiscord(Alert{Retailer: "Scan.co.uk", Term: "RTX 3070", Products: products})
+
+	assert.Len(t, messages, 2)
+	assert.Len(t, messages[0].Embeds, discordMaxEmbeds)
+	assert.Len(t, messages[1].Embeds, 1)
+	assert.Equal(t, "Stock found on Scan.co.uk for RTX 3070", messages[0].Content)
+
+	// Test the embed contents
+	embed := messages[0].Embeds[0]
+	assert.Equal(t, "RTX 3070", embed.Title)
+	assert.Equal(t, "https://example.com/rtx", embed.URL)
+	assert.Equal(t, "https://example.com/rtx.jpg", embed.Thumbnail.URL)
+	assert.Equal(t, "£499.99", embed.Fields[0].Value)
+	assert.Equal(t, "Scan.co.uk", embed.Fields[1].Value)
+	assert.Equal(t, "RTX 3070", embed.Fields[2].Value)
+}
+
+// TestSendDiscord tests sending
+// messages to a Discord webhook
+func TestSendDiscord(t *testing.T) {
+	c := GetTestContext()
+
+	var received int
+
+	// Build a mock HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		message := new(DiscordMessage)
+		assert.Nil(t, json.NewDecoder(req.Body).Decode(message))
+		assert.Equal(t, discordUsername, message.Username)
+
+		received++
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+
+	// Close the server when test finishes
+	defer server.Close()
+
+	err := c.SendDiscord(context.Background(), server.URL, BuildDiscord(Alert{Retailer: "test", Term: "test", Products: []Product{{Name: "test"}}}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, received)
+}
@@ -1,29 +1,21 @@
 package notifier
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-// TestFetchEbuyer ensures the function is parsing
-// products correctly, the retailer is not mocked
+// TestFetchEbuyer ensures the function is parsing products
+// correctly from the recorded responses of the retailer
 func TestFetchEbuyer(t *testing.T) {
-	c := GetTestContext()
-
-	// Test filter that should return products
-	// we will actually scrape the retailer live
-	filter := Filter{
-		Term:     "RTX 3070",
-		MinPrice: 500,
-		MaxPrice: 800,
-	}
+	c := GetCassetteContext(t, "ebuyer")
+	filter := Filter{Term: "RTX 3070"}
 
 	// Check the retailer
-	response, err := c.FetchEbuyer(filter, &[]Product{}, 1, 1)
-	response.Parsed = len(response.Matches)
-	response.Matches = FilterProducts(response.Matches, filter)
+	response, err := c.FetchEbuyer(context.Background(), filter)
 
 	assert.Nil(t, err)
-	assert.NotEqual(t, 0, response.Parsed)
+	assertRecorded(t, response, filter, "www.ebuyer.com")
 }
@@ -0,0 +1,226 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alexlast/stock-notifier/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	parserProblemPolls    = 3
+	parserEmpty           = "empty"
+	parserUnnamed         = "unnamed"
+	parserUnpriced        = "unpriced"
+	parserDegradedFormat  = "The parser for %s looks broken polling %s: %s. Check whether the retailer has changed its pages."
+	parserRecoveredFormat = "The parser for %s is parsing products again polling %s."
+)
+
+// parserState tracks the health of the parser
+// for a single retailer and filter
+type parserState struct {
+	parsed   int
+	polls    int
+	accepted string
+	degraded bool
+}
+
+// ParserHealth validates the products parsed by each poll so
+// retailers changing their pages are noticed, a poll that
+// parses nothing where it used to, or parses products
+// missing names or prices, marks the parser degraded, a
+// problem that persists is accepted after a few polls
+type ParserHealth struct {
+	mu        sync.Mutex
+	threshold float64
+	states    map[string]*parserState
+	degraded  map[string]int
+}
+
+// NewParserHealth returns a health tracker flagging polls where
+// more than the configured share of products are incomplete
+func NewParserHealth(config *Config) *ParserHealth {
+	return &ParserHealth{
+		threshold: config.ParserThreshold,
+		states:    map[string]*parserState{},
+		degraded:  map[string]int{},
+	}
+}
+
+// Check validates the products parsed by a poll of the retailer for
+// the filter, returning the problem found if the parser looks broken
+// and whether the parser health changed since the last poll
+func (h *ParserHealth) Check(retailer string, filter Filter, products []Product) (problem string, changed bool) {
+	if h == nil {
+		return "", false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	scope := filter.scope(retailer)
+	state, ok := h.states[scope]
+
+	if !ok {
+		state = &parserState{}
+		h.states[scope] = state
+	}
+
+	kind, problem := h.validate(state, products)
+
+	// Accept a problem that persists as how the retailer lists
+	// the term, such as a product no longer listed or
+	// listed without a price
+	switch {
+	case kind != "" && kind == state.accepted:
+		problem = ""
+	case kind != "":
+		state.polls++
+
+		if state.polls >= parserProblemPolls {
+			state.accepted = kind
+			problem = ""
+		}
+	default:
+		state.accepted = ""
+	}
+
+	// Remember the last healthy count so a
+	// broken parser stays degraded
+	if problem == "" {
+		state.parsed = len(products)
+		state.polls = 0
+	}
+
+	if (problem != "") == state.degraded {
+		return problem, false
+	}
+
+	state.degraded = problem != ""
+
+	if state.degraded {
+		h.degraded[retailer]++
+	} else {
+		h.degraded[retailer]--
+	}
+
+	// The retailer is degraded while any filter is
+	value := 0.0
+
+	if h.degraded[retailer] > 0 {
+		value = 1
+	}
+
+	metrics.ParserDegraded.With(
+		prometheus.Labels{"retailer": retailer}).Set(value)
+
+	return problem, true
+}
+
+// validate returns the kind of problem with the parsed
+// products and its description, the lock must be held
+func (h *ParserHealth) validate(state *parserState, products []Product) (string, string) {
+	if len(products) == 0 {
+		if state.parsed > 0 {
+			return parserEmpty, fmt.Sprintf("no products were parsed, the last healthy poll parsed %d", state.parsed)
+		}
+
+		return "", ""
+	}
+
+	var unnamed, unpriced int
+
+	for _, product := range products {
+		if product.Name == "" {
+			unnamed++
+		}
+
+		if product.Price <= 0 {
+			unpriced++
+		}
+	}
+
+	limit := h.threshold * float64(len(products))
+
+	switch {
+	case float64(unnamed) > limit:
+		return parserUnnamed, fmt.Sprintf("%d of %d products have no name", unnamed, len(products))
+	case float64(unpriced) > limit:
+		return parserUnpriced, fmt.Sprintf("%d of %d products have no price", unpriced, len(products))
+	}
+
+	return "", ""
+}
+
+// Degraded checks whether the parser of the retailer
+// is degraded for any filter
+func (h *ParserHealth) Degraded(retailer string) bool {
+	if h == nil {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.degraded[retailer] > 0
+}
+
+// NotifyParserHealth sends an operator alert to every admin
+// notify entry when the parser of a retailer degrades
+// or recovers, an empty problem means recovered
+func (c *Context) NotifyParserHealth(ctx context.Context, retailer string, filter Filter, problem string) error {
+	alert := Alert{
+		Event:    EventParserRecovered,
+		Retailer: retailer,
+		Term:     filter.Term,
+		Message:  fmt.Sprintf(parserRecoveredFormat, retailer, filter.Term),
+	}
+
+	if problem != "" {
+		alert.Event = EventParserDegraded
+		alert.Message = fmt.Sprintf(parserDegradedFormat, retailer, filter.Term, problem)
+	}
+
+	errs := ChannelErrors{}
+
+	for i, notify := range c.Config.AdminNotify {
+		err := c.sendAlert(ctx, alert, notify)
+
+		if err != nil {
+			errs[fmt.Sprintf(notifyErrorFormat, alert.Event, i)] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// checkParser validates the products of a poll, logging and
+// alerting operators when the parser health changes
+func (c *Context) checkParser(ctx context.Context, retailer string, filter Filter, products []Product) string {
+	problem, changed := c.Health.Check(retailer, filter, products)
+
+	if !changed {
+		return problem
+	}
+
+	if problem != "" {
+		log.Warnf("Parser for %s degraded polling %s, %s", retailer, filter.Term, problem)
+	} else {
+		log.Infof("Parser for %s recovered polling %s", retailer, filter.Term)
+	}
+
+	err := c.NotifyParserHealth(ctx, retailer, filter, problem)
+
+	if err != nil {
+		log.Errorf("Unable to send admin notification, error: %v", err)
+	}
+
+	return problem
+}
@@ -0,0 +1,282 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParserHealthCheck ensures polls missing products,
+// names or prices degrade the parser until it recovers
+func TestParserHealthCheck(t *testing.T) {
+	h := NewParserHealth(&Config{ParserThreshold: 0.5})
+	filter := Filter{Term: "RTX 3070"}
+
+	healthy := []Product{{Name: "RTX 3070", Price: 499.99}, {Name: "RTX 3070 Ti", Price: 599.99}}
+
+	// A term without results isn't a problem
+	problem, changed := h.Check("test-health", filter, nil)
+	assert.Empty(t, problem)
+	assert.False(t, changed)
+
+	problem, changed = h.Check("test-health", filter, healthy)
+	assert.Empty(t, problem)
+	assert.False(t, changed)
+
+	// Products disappearing degrades the parser
+	problem, changed = h.Check("test-health", filter, nil)
+	assert.Contains(t, problem, "no products were parsed")
+	assert.True(t, changed)
+	assert.True(t, h.Degraded("test-health"))
+
+	// Staying broken doesn't change the health
+	problem, changed = h.Check("test-health", filter, nil)
+	assert.NotEmpty(t, problem)
+	assert.False(t, changed)
+
+	problem, changed = h.Check("test-health", filter, healthy)
+	assert.Empty(t, problem)
+	assert.True(t, changed)
+	assert.False(t, h.Degraded("test-health"))
+
+	// Most products missing prices or names
+	problem, _ = h.Check("test-health", filter, []Product{{Name: "RTX 3070"}, {Name: "RTX 3070 Ti"}, {Name: "RTX 3080", Price: 699.99}})
+	assert.Equal(t, "2 of 3 products have no price", problem)
+
+	problem, _ = h.Check("test-health", filter, []Product{{Price: 499.99}, {Price: 599.99}})
+	assert.Equal(t, "2 of 2 products have no name", problem)
+
+	// A few incomplete products are tolerated
+	problem, _ = h.Check("test-health", filter, []Product{{Name: "RTX 3070"}, {Name: "RTX 3070 Ti", Price: 599.99}})
+	assert.Empty(t, problem)
+}
+
+// TestParserHealthEmpty ensures a term that stays empty
+// is re-baselined instead of staying degraded
+func TestParserHealthEmpty(t *testing.T) {
+	h := NewParserHealth(&Config{ParserThreshold: 0.5})
+	filter := Filter{Term: "RTX 3070"}
+	healthy := []Product{{Name: "RTX 3070", Price: 499.99}}
+
+	h.Check("test-empty", filter, healthy)
+
+	for i := 1; i < parserProblemPolls; i++ {
+		problem, _ := h.Check("test-empty", filter, nil)
+		assert.NotEmpty(t, problem)
+		assert.True(t, h.Degraded("test-empty"))
+	}
+
+	problem, changed := h.Check("test-empty", filter, nil)
+	assert.Empty(t, problem)
+	assert.True(t, changed)
+	assert.False(t, h.Degraded("test-empty"))
+
+	// Staying empty is now healthy
+	problem, changed = h.Check("test-empty", filter, nil)
+	assert.Empty(t, problem)
+	assert.False(t, changed)
+
+	// The count restarts after a healthy poll
+	h.Check("test-empty", filter, healthy)
+	h.Check("test-empty", filter, nil)
+	h.Check("test-empty", filter, healthy)
+
+	problem, _ = h.Check("test-empty", filter, nil)
+	assert.NotEmpty(t, problem)
+}
+
+// TestParserHealthUnpriced ensures a term that keeps listing
+// unpriced products is accepted until it recovers
+func TestParserHealthUnpriced(t *testing.T) {
+	h := NewParserHealth(&Config{ParserThreshold: 0.5})
+	filter := Filter{Term: "RTX 3070"}
+	healthy := []Product{{Name: "RTX 3070", Price: 499.99}}
+	unpriced := []Product{{Name: "RTX 3070"}}
+
+	for i := 1; i < parserProblemPolls; i++ {
+		problem, _ := h.Check("test-unpriced", filter, unpriced)
+		assert.Equal(t, "1 of 1 products have no price", problem)
+	}
+
+	problem, changed := h.Check("test-unpriced", filter, unpriced)
+	assert.Empty(t, problem)
+	assert.True(t, changed)
+	assert.False(t, h.Degraded("test-unpriced"))
+
+	problem, changed = h.Check("test-unpriced", filter, unpriced)
+	assert.Empty(t, problem)
+	assert.False(t, changed)
+
+	// Other problems still degrade the parser
+	problem, _ = h.Check("test-unpriced", filter, []Product{{Price: 499.99}})
+	assert.NotEmpty(t, problem)
+
+	// Prices returning resets what is accepted
+	h.Check("test-unpriced", filter, healthy)
+
+	problem, _ = h.Check("test-unpriced", filter, unpriced)
+	assert.NotEmpty(t, problem)
+}
+
+// TestParserHealthScopes ensures a retailer is degraded
+// while the parser is degraded for any filter
+func TestParserHealthScopes(t *testing.T) {
+	var h *ParserHealth
+
+	problem, changed := h.Check("test-scopes", Filter{Term: "RTX 3070"}, nil)
+	assert.Empty(t, problem)
+	assert.False(t, changed)
+	assert.False(t, h.Degraded("test-scopes"))
+
+	h = NewParserHealth(&Config{ParserThreshold: 0.5})
+	products := []Product{{Name: "RTX 3070", Price: 499.99}}
+
+	for _, term := range []string{"RTX 3070", "RTX 3080"} {
+		h.Check("test-scopes", Filter{Term: term}, products)
+		h.Check("test-scopes", Filter{Term: term}, nil)
+	}
+
+	h.Check("test-scopes", Filter{Term: "RTX 3070"}, products)
+	assert.True(t, h.Degraded("test-scopes"))
+
+	h.Check("test-scopes", Filter{Term: "RTX 3080"}, products)
+	assert.False(t, h.Degraded("test-scopes"))
+}
+
+// TestPollRetailerParserDegraded ensures degraded polls alert
+// the admin notify target and don't raise product alerts
+func TestPollRetailerParserDegraded(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []WebhookPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := WebhookPayload{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var products []Product
+
+	retailer := &retailerFunc{
+		name: "test-degraded",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			return Response{Matches: products}, nil
+		},
+	}
+
+	c := GetTestContext()
+	c.Config = &Config{
+		AlertMode:     alertModeTransition,
+		SoldOutAlerts: true,
+		AdminNotify:   NotifyDecoder{{Webhook: &Webhook{URL: server.URL + "/admin"}}},
+		Notify:        NotifyDecoder{{Webhook: &Webhook{URL: server.URL + "/users"}}},
+	}
+	c.Health = NewParserHealth(&Config{ParserThreshold: 0.5})
+
+	filter := Filter{Term: "RTX 3070", MaxPrice: 1000}
+	products = []Product{{Name: "RTX 3070", Price: 499.99, InStock: true, URL: "https://test/rtx-3070"}}
+
+	c.PollRetailer(context.Background(), retailer, filter)
+	assert.Len(t, payloads, 1)
+	assert.Equal(t, EventInStock, payloads[0].Event)
+
+	// The empty poll alerts admins instead of
+	// users being told the product sold out
+	products = nil
+	c.PollRetailer(context.Background(), retailer, filter)
+
+	assert.Len(t, payloads, 2)
+	assert.Equal(t, EventParserDegraded, payloads[1].Event)
+	assert.Contains(t, payloads[1].Message, "no products were parsed")
+
+	// Recovery is sent once the parser works again
+	products = []Product{{Name: "RTX 3070", Price: 499.99, InStock: true, URL: "https://test/rtx-3070"}}
+	c.PollRetailer(context.Background(), retailer, filter)
+
+	assert.Len(t, payloads, 3)
+	assert.Equal(t, EventParserRecovered, payloads[2].Event)
+
+	// A partly broken parser still alerts restocks but
+	// not products it can no longer price as sold out
+	products = []Product{
+		{Name: "RTX 3070", InStock: true},
+		{Name: "RTX 3070 Ti", InStock: true},
+		{Name: "RTX 3070 OC", Price: 549.99, InStock: true},
+	}
+	c.PollRetailer(context.Background(), retailer, filter)
+
+	assert.Len(t, payloads, 5)
+	assert.Equal(t, EventParserDegraded, payloads[3].Event)
+	assert.Equal(t, EventInStock, payloads[4].Event)
+	assert.Equal(t, "RTX 3070 OC", payloads[4].Products[0].Name)
+
+	// Nothing is alerted again once it recovers
+	products = []Product{
+		{Name: "RTX 3070", Price: 499.99, InStock: true},
+		{Name: "RTX 3070 OC", Price: 549.99, InStock: true},
+	}
+	c.PollRetailer(context.Background(), retailer, filter)
+
+	assert.Len(t, payloads, 6)
+	assert.Equal(t, EventParserRecovered, payloads[5].Event)
+}
+
+// TestNotifyParserHealthErrors ensures an error
+// is kept for every failing admin target
+func TestNotifyParserHealthErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := GetTestContext()
+	c.Config = &Config{
+		AdminNotify: NotifyDecoder{
+			{Webhook: &Webhook{URL: server.URL + "/first"}},
+			{Webhook: &Webhook{URL: server.URL + "/second"}},
+		},
+	}
+
+	err := c.NotifyParserHealth(context.Background(), "test", Filter{Term: "RTX 3070"}, "no products were parsed")
+
+	assert.IsType(t, ChannelErrors{}, err)
+	assert.Len(t, err, 2)
+	assert.Contains(t, err.Error(), "parser_degraded to notify 0")
+	assert.Contains(t, err.Error(), "parser_degraded to notify 1")
+	assert.NotContains(t, err.Error(), "/first")
+}
+
+// TestParserAlertChannels ensures alerts without
+// products are rendered by every channel
+func TestParserAlertChannels(t *testing.T) {
+	alert := Alert{
+		Event:    EventParserDegraded,
+		Retailer: "Scan.co.uk",
+		Term:     "RTX 3070",
+		Message:  "2 of 2 products have no price",
+	}
+
+	discord := BuildDiscord(alert)
+	assert.Len(t, discord, 1)
+	assert.Equal(t, "Parser degraded on Scan.co.uk for RTX 3070\n2 of 2 products have no price", discord[0].Content)
+
+	slack := BuildSlack(alert)
+	assert.Len(t, slack, 1)
+	assert.Len(t, slack[0].Blocks, 2)
+	assert.Equal(t, alert.Message, slack[0].Blocks[1].Text.Text)
+
+	telegram := BuildTelegram("123", alert)
+	assert.Len(t, telegram, 1)
+	assert.Contains(t, telegram[0].Text, alert.Message)
+
+	assert.Equal(t, alert.Message, BuildWebhook(alert).Message)
+}
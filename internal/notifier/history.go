@@ -0,0 +1,353 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	historyKeyFormat  = "%s:%s"
+	baselineKeyFormat = "%s:%s"
+	historyMaxPoints  = 1000
+	priceDropFormat   = "The following products have dropped in price on %s: \n\n%s"
+)
+
+// PricePoint defines a price observed
+// for a product at a point in time
+type PricePoint struct {
+	Price float64   `json:"price"`
+	Time  time.Time `json:"time"`
+}
+
+// PriceBaseline defines the prices a filter compares a product
+// against, the reference is the highest price since the last
+// drop alert so prices falling in small steps still alert
+type PriceBaseline struct {
+	Reference float64 `json:"reference"`
+	Lowest    float64 `json:"lowest"`
+}
+
+// ProductHistory defines the prices recorded
+// for a product at a retailer
+type ProductHistory struct {
+	Retailer string       `json:"retailer"`
+	Name     string       `json:"name"`
+	Lowest   PricePoint   `json:"lowest"`
+	Points   []PricePoint `json:"points"`
+}
+
+// PriceHistory records the prices observed for every product per
+// retailer and the baseline of every filter watching the product,
+// optionally persisted to a file
+type PriceHistory struct {
+	sync.RWMutex
+	path      string
+	entries   map[string][]PricePoint
+	baselines map[string]PriceBaseline
+}
+
+// historyFile defines the structure
+// of the persisted history
+type historyFile struct {
+	Entries   map[string][]PricePoint  `json:"entries"`
+	Baselines map[string]PriceBaseline `json:"baselines"`
+}
+
+// NewPriceHistory returns a new price history persisted to
+// the file at path, an empty path keeps history in memory
+func NewPriceHistory(path string) (*PriceHistory, error) {
+	h := &PriceHistory{
+		path:      path,
+		entries:   map[string][]PricePoint{},
+		baselines: map[string]PriceBaseline{},
+	}
+
+	if path == "" {
+		return h, nil
+	}
+
+	raw, err := ioutil.ReadFile(path)
+
+	// No history has been recorded yet
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read history file %s, error: %v", path, err)
+	}
+
+	file := historyFile{}
+	err = json.Unmarshal(raw, &file)
+
+	// Older files only hold the entries
+	if err == nil && file.Entries == nil {
+		err = json.Unmarshal(raw, &file.Entries)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal history file %s, error: %v", path, err)
+	}
+
+	if file.Entries != nil {
+		h.entries = file.Entries
+	}
+
+	if file.Baselines != nil {
+		h.baselines = file.Baselines
+	}
+
+	return h, nil
+}
+
+// Record adds the price of each in stock product to the history
+// and returns a transition for any product whose price dropped
+// by the amounts configured on the filter, each filter keeps its
+// own baseline so filters sharing a product all see the drop
+func (h *PriceHistory) Record(retailer string, filter Filter, products []Product) ([]Transition, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.baselines == nil {
+		h.baselines = map[string]PriceBaseline{}
+	}
+
+	var drops []Transition
+	var changed bool
+
+	for _, product := range products {
+		// Only track real prices for available products
+		if !product.InStock || product.Price <= 0 {
+			continue
+		}
+
+		key := fmt.Sprintf(historyKeyFormat, retailer, product.Name)
+		points := h.entries[key]
+
+		// Check the price against the filter baseline
+		baselineKey := fmt.Sprintf(baselineKeyFormat, filter.scope(retailer), product.Name)
+		previous, seen := h.baselines[baselineKey]
+
+		if !seen {
+			previous = newPriceBaseline(points, product.Price)
+		}
+
+		baseline := previous
+
+		switch {
+		case product.PriceMatch(filter) && filter.PriceDropped(baseline, product.Price):
+			drops = append(drops, Transition{
+				Event:         EventPriceDrop,
+				Product:       product,
+				PreviousPrice: baseline.Reference,
+			})
+
+			baseline.Reference = product.Price
+		case product.Price > baseline.Reference:
+			baseline.Reference = product.Price
+		}
+
+		if product.Price < baseline.Lowest {
+			baseline.Lowest = product.Price
+		}
+
+		if !seen || baseline != previous {
+			h.baselines[baselineKey] = baseline
+			changed = true
+		}
+
+		// Only record price changes
+		if len(points) > 0 && points[len(points)-1].Price == product.Price {
+			continue
+		}
+
+		points = append(points, PricePoint{Price: product.Price, Time: time.Now()})
+
+		// Cap the history for each product
+		if len(points) > historyMaxPoints {
+			points = points[len(points)-historyMaxPoints:]
+		}
+
+		h.entries[key] = points
+		changed = true
+	}
+
+	if changed && h.path != "" {
+		return drops, h.write()
+	}
+
+	return drops, nil
+}
+
+// newPriceBaseline returns the baseline of a filter
+// that hasn't seen the product before, starting
+// from the last and lowest recorded prices
+func newPriceBaseline(points []PricePoint, price float64) PriceBaseline {
+	if len(points) == 0 {
+		return PriceBaseline{Reference: price, Lowest: price}
+	}
+
+	return PriceBaseline{
+		Reference: points[len(points)-1].Price,
+		Lowest:    lowestPrice(points).Price,
+	}
+}
+
+// History returns every price observed for
+// the product at the retailer, oldest first
+func (h *PriceHistory) History(retailer, name string) []PricePoint {
+	h.RLock()
+	defer h.RUnlock()
+
+	points := h.entries[fmt.Sprintf(historyKeyFormat, retailer, name)]
+
+	return append([]PricePoint(nil), points...)
+}
+
+// Lowest returns the lowest price observed
+// for the product at the retailer
+func (h *PriceHistory) Lowest(retailer, name string) (PricePoint, bool) {
+	points := h.History(retailer, name)
+
+	if len(points) == 0 {
+		return PricePoint{}, false
+	}
+
+	return lowestPrice(points), true
+}
+
+// Products returns the history of every product sorted by retailer
+// and name, an empty retailer or name matches every product
+func (h *PriceHistory) Products(retailer, name string) []ProductHistory {
+	products := []ProductHistory{}
+
+	h.RLock()
+	defer h.RUnlock()
+
+	for key, points := range h.entries {
+		// Retailer names never contain a colon
+		parts := strings.SplitN(key, ":", 2)
+
+		if len(parts) != 2 || len(points) == 0 {
+			continue
+		}
+
+		if (retailer != "" && !strings.EqualFold(parts[0], retailer)) || (name != "" && !strings.EqualFold(parts[1], name)) {
+			continue
+		}
+
+		products = append(products, ProductHistory{
+			Retailer: parts[0],
+			Name:     parts[1],
+			Lowest:   lowestPrice(points),
+			Points:   append([]PricePoint(nil), points...),
+		})
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Retailer != products[j].Retailer {
+			return products[i].Retailer < products[j].Retailer
+		}
+
+		return products[i].Name < products[j].Name
+	})
+
+	return products
+}
+
+// Flush persists the history to the file
+// if the history isn't kept in memory
+func (h *PriceHistory) Flush() error {
+	if h.path == "" {
+		return nil
+	}
+
+	h.RLock()
+	defer h.RUnlock()
+
+	return h.write()
+}
+
+// write persists the history to the file
+func (h *PriceHistory) write() error {
+	raw, err := json.Marshal(historyFile{Entries: h.entries, Baselines: h.baselines})
+
+	if err != nil {
+		return fmt.Errorf("Unable to marshal history, error: %v", err)
+	}
+
+	err = writeFileAtomic(h.path, raw)
+
+	if err != nil {
+		return fmt.Errorf("Unable to write history file %s, error: %v", h.path, err)
+	}
+
+	return nil
+}
+
+// PriceDropped checks whether the price has dropped
+// compared to the baseline by the amounts configured
+// on the filter
+func (f Filter) PriceDropped(baseline PriceBaseline, price float64) bool {
+	drop := baseline.Reference - price
+
+	if drop <= 0 {
+		return false
+	}
+
+	if f.DropAmount > 0 && drop >= f.DropAmount {
+		return true
+	}
+
+	if f.DropPercent > 0 && (drop/baseline.Reference)*100 >= f.DropPercent {
+		return true
+	}
+
+	if f.LowestPrice && price < baseline.Lowest {
+		return true
+	}
+
+	return false
+}
+
+// withDrops adds the price drops to the transitions, dropping
+// the price change of any product that dropped so a single
+// price cut only sends a price drop alert
+func withDrops(transitions, drops []Transition) []Transition {
+	dropped := map[string]bool{}
+
+	for _, drop := range drops {
+		dropped[drop.Product.Name] = true
+	}
+
+	var merged []Transition
+
+	for _, transition := range transitions {
+		if transition.Event == EventPriceChange && dropped[transition.Product.Name] {
+			continue
+		}
+
+		merged = append(merged, transition)
+	}
+
+	return append(merged, drops...)
+}
+
+// lowestPrice returns the lowest price point
+func lowestPrice(points []PricePoint) PricePoint {
+	lowest := points[0]
+
+	for _, point := range points[1:] {
+		if point.Price < lowest.Price {
+			lowest = point
+		}
+	}
+
+	return lowest
+}
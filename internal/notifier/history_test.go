@@ -0,0 +1,241 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecord tests price history is recorded
+// and drops are detected
+func TestRecord(t *testing.T) {
+	h, err := NewPriceHistory("")
+	assert.Nil(t, err)
+
+	filter := Filter{Term: "RTX 3070", MaxPrice: 1000, DropAmount: 50}
+
+	// First observation never drops
+	drops, err := h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 600, InStock: true}})
+	assert.Nil(t, err)
+	assert.Empty(t, drops)
+
+	// Small drops are ignored
+	drops, _ = h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 580, InStock: true}})
+	assert.Empty(t, drops)
+
+	// Unchanged and out of stock prices aren't recorded
+	h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 580, InStock: true}})
+	h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 100}})
+
+	// Drop by the configured amount since the last alert
+	drops, _ = h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 540, InStock: true}})
+	assert.Len(t, drops, 1)
+	assert.Equal(t, EventPriceDrop, drops[0].Event)
+	assert.Equal(t, float64(600), drops[0].PreviousPrice)
+
+	// The alert resets the baseline
+	drops, _ = h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 520, InStock: true}})
+	assert.Empty(t, drops)
+
+	// Test the history can be queried
+	points := h.History("test", "RTX 3070")
+	assert.Len(t, points, 4)
+	assert.Equal(t, float64(520), points[3].Price)
+
+	lowest, ok := h.Lowest("test", "RTX 3070")
+	assert.True(t, ok)
+	assert.Equal(t, float64(520), lowest.Price)
+
+	_, ok = h.Lowest("test", "missing")
+	assert.False(t, ok)
+}
+
+// TestRecordFilters ensures filters with different
+// thresholds on the same product all see a drop
+func TestRecordFilters(t *testing.T) {
+	h, err := NewPriceHistory("")
+	assert.Nil(t, err)
+
+	amount := Filter{Term: "RTX 3070", MaxPrice: 1000, DropAmount: 50}
+	percent := Filter{Term: "3070", MaxPrice: 1000, DropPercent: 5}
+
+	for _, filter := range []Filter{amount, percent} {
+		drops, _ := h.Record("test", filter, []Product{{Name: "RTX 3070", Price: 600, InStock: true}})
+		assert.Empty(t, drops)
+	}
+
+	// Only the percentage is met
+	drops, _ := h.Record("test", amount, []Product{{Name: "RTX 3070", Price: 560, InStock: true}})
+	assert.Empty(t, drops)
+
+	drops, _ = h.Record("test", percent, []Product{{Name: "RTX 3070", Price: 560, InStock: true}})
+	assert.Len(t, drops, 1)
+	assert.Equal(t, float64(600), drops[0].PreviousPrice)
+
+	// The amount filter catches up on the next cut
+	drops, _ = h.Record("test", amount, []Product{{Name: "RTX 3070", Price: 540, InStock: true}})
+	assert.Len(t, drops, 1)
+	assert.Equal(t, float64(600), drops[0].PreviousPrice)
+
+	drops, _ = h.Record("test", percent, []Product{{Name: "RTX 3070", Price: 540, InStock: true}})
+	assert.Empty(t, drops)
+
+	// The price point is only recorded once
+	assert.Len(t, h.History("test", "RTX 3070"), 3)
+}
+
+// TestRecordSteps ensures prices falling in small
+// steps alert once the total drop is large enough
+func TestRecordSteps(t *testing.T) {
+	h, err := NewPriceHistory("")
+	assert.Nil(t, err)
+
+	filter := Filter{Term: "RTX 3070", MaxPrice: 1000, DropPercent: 10}
+	alerts := 0
+
+	for _, price := range []float64{500, 520, 500, 490, 480, 470, 460} {
+		drops, _ := h.Record("test", filter, []Product{{Name: "RTX 3070", Price: price, InStock: true}})
+		alerts += len(drops)
+	}
+
+	// 460 is over 10% below the 520 peak
+	assert.Equal(t, 1, alerts)
+}
+
+// TestPriceDropped tests each of
+// the price drop thresholds
+func TestPriceDropped(t *testing.T) {
+	baseline := PriceBaseline{Reference: 450, Lowest: 400}
+
+	assert.True(t, Filter{DropAmount: 40}.PriceDropped(baseline, 410))
+	assert.False(t, Filter{DropAmount: 50}.PriceDropped(baseline, 410))
+	assert.True(t, Filter{DropPercent: 10}.PriceDropped(baseline, 405))
+	assert.False(t, Filter{DropPercent: 10}.PriceDropped(baseline, 406))
+	assert.True(t, Filter{LowestPrice: true}.PriceDropped(baseline, 399))
+	assert.False(t, Filter{LowestPrice: true}.PriceDropped(baseline, 400))
+
+	// Price rises never drop
+	assert.False(t, Filter{DropAmount: 1}.PriceDropped(baseline, 460))
+}
+
+// TestWithDrops ensures a price drop replaces
+// the price change of the same product
+func TestWithDrops(t *testing.T) {
+	transitions := []Transition{
+		{Event: EventPriceChange, Product: Product{Name: "RTX 3070"}},
+		{Event: EventPriceChange, Product: Product{Name: "RTX 3080"}},
+		{Event: EventInStock, Product: Product{Name: "RTX 3090"}},
+	}
+
+	merged := withDrops(transitions, []Transition{{Event: EventPriceDrop, Product: Product{Name: "RTX 3070"}}})
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "RTX 3080", merged[0].Product.Name)
+	assert.Equal(t, EventInStock, merged[1].Event)
+	assert.Equal(t, EventPriceDrop, merged[2].Event)
+}
+
+// TestPollRetailerDropTTL ensures a price cut in ttl mode
+// sends the price drop alert without a stock alert
+func TestPollRetailerDropTTL(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := WebhookPayload{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		events = append(events, payload.Event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	price := 500.0
+
+	retailer := &retailerFunc{
+		name: "test-drop-ttl",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			return Response{Matches: []Product{{Name: "RTX 3070", Price: price, InStock: true}}}, nil
+		},
+	}
+
+	c := GetTestContext()
+	c.Config = &Config{
+		CacheTTL: 3600,
+		Notify:   NotifyDecoder{{Webhook: &Webhook{URL: server.URL}}},
+	}
+
+	filter := Filter{Term: "RTX 3070", MaxPrice: 1000, DropAmount: 50}
+
+	c.PollRetailer(context.Background(), retailer, filter)
+	assert.Equal(t, []string{EventInStock}, events)
+
+	price = 400
+	c.PollRetailer(context.Background(), retailer, filter)
+	assert.Equal(t, []string{EventInStock, EventPriceDrop}, events)
+
+	// The dropped price isn't alerted again
+	c.PollRetailer(context.Background(), retailer, filter)
+	assert.Len(t, events, 2)
+}
+
+// TestPriceHistoryFile ensures the
+// history survives a restart
+func TestPriceHistoryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "history.json")
+	h, err := NewPriceHistory(path)
+	assert.Nil(t, err)
+
+	_, err = h.Record("test", Filter{}, []Product{{Name: "test", Price: 100, InStock: true}})
+	assert.Nil(t, err)
+
+	// Load the history again
+	h, err = NewPriceHistory(path)
+	assert.Nil(t, err)
+	assert.Len(t, h.History("test", "test"), 1)
+	assert.Len(t, h.baselines, 1)
+
+	// Files written before baselines were kept
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"test:old": [{"price": 100}]}`), 0644))
+
+	h, err = NewPriceHistory(path)
+	assert.Nil(t, err)
+	assert.Len(t, h.History("test", "old"), 1)
+}
+
+// TestPriceHistoryProducts ensures the history
+// can be listed per retailer and product
+func TestPriceHistoryProducts(t *testing.T) {
+	h, err := NewPriceHistory("")
+	assert.Nil(t, err)
+
+	filter := Filter{Term: "RTX", MaxPrice: 1000}
+
+	h.Record("Scan.co.uk", filter, []Product{{Name: "RTX 3080", Price: 700, InStock: true}, {Name: "RTX 3070", Price: 500, InStock: true}})
+	h.Record("Scan.co.uk", filter, []Product{{Name: "RTX 3070", Price: 450, InStock: true}})
+	h.Record("Ebuyer.com", filter, []Product{{Name: "RTX 3070", Price: 480, InStock: true}})
+
+	products := h.Products("", "")
+	assert.Len(t, products, 3)
+	assert.Equal(t, "Ebuyer.com", products[0].Retailer)
+	assert.Equal(t, "RTX 3070", products[1].Name)
+
+	products = h.Products("scan.co.uk", "RTX 3070")
+	assert.Len(t, products, 1)
+	assert.Len(t, products[0].Points, 2)
+	assert.Equal(t, float64(450), products[0].Lowest.Price)
+}
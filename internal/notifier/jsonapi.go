@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	// jsonPrice extracts a number from a formatted price
+	jsonPrice = regexp.MustCompile(`[0-9][0-9,]*(\.[0-9]+)?`)
+
+	// jsonPlaceholder matches a path in a template
+	jsonPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+)
+
+// JSONDefinition holds the gjson paths used to read products from
+// a JSON search API, products is read from the response and the
+// rest from each product, url and image may also be templates
+// such as `/product/{id}` with paths in braces
+type JSONDefinition struct {
+	Products string `json:"products" yaml:"products"`
+	Pages    string `json:"pages" yaml:"pages"`
+	Title    string `json:"title" yaml:"title"`
+	URL      string `json:"url" yaml:"url"`
+	SKU      string `json:"sku" yaml:"sku"`
+	Image    string `json:"image" yaml:"image"`
+	Price    string `json:"price" yaml:"price"`
+	Stock    string `json:"stock" yaml:"stock"`
+	InStock  string `json:"inStock" yaml:"inStock"`
+}
+
+// JSONRetailer is a retailer read from a JSON
+// search API with the paths of its definition
+type JSONRetailer struct {
+	definition RetailerDefinition
+}
+
+// NewJSONRetailer validates the definition
+// and builds it into a retailer
+func NewJSONRetailer(definition RetailerDefinition) (*JSONRetailer, error) {
+	err := validateDefinition(definition)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if definition.JSON == nil || definition.JSON.Products == "" || definition.JSON.Title == "" {
+		return nil, fmt.Errorf("Retailer %s requires products and title paths", definition.Name)
+	}
+
+	// Templates need a closing brace for every path
+	for _, template := range []string{definition.JSON.URL, definition.JSON.Image} {
+		if strings.Count(template, "{") != strings.Count(template, "}") {
+			return nil, fmt.Errorf("Invalid template %s in retailer %s, unbalanced braces", template, definition.Name)
+		}
+	}
+
+	return &JSONRetailer{definition: definition}, nil
+}
+
+// mustJSONRetailer builds a built in retailer,
+// panicking on an invalid definition
+func mustJSONRetailer(definition RetailerDefinition) *JSONRetailer {
+	r, err := NewJSONRetailer(definition)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Name returns the display name of the retailer
+func (r *JSONRetailer) Name() string {
+	return r.definition.Name
+}
+
+// Fetch returns all products found by the retailer for the filter
+func (r *JSONRetailer) Fetch(ctx context.Context, c *Context, filter Filter) (Response, error) {
+	return c.paginate(ctx, func(ctx context.Context, page int) ([]Product, int, error) {
+		return r.fetchPage(ctx, c, filter, page)
+	})
+}
+
+// fetchPage fetches and parses a single page of results
+func (r *JSONRetailer) fetchPage(ctx context.Context, c *Context, filter Filter, cPage int) ([]Product, int, error) {
+	pageURL := searchURL(r.definition.Search, filter.Term, cPage)
+	raw, err := c.getRaw(ctx, pageURL)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return r.parse(pageURL, raw)
+}
+
+// parse extracts the products and final
+// page number from an API response
+func (r *JSONRetailer) parse(pageURL string, raw []byte) ([]Product, int, error) {
+	var matches []Product
+
+	if !gjson.ValidBytes(raw) {
+		return nil, 0, fmt.Errorf("Unable to parse response for %s, invalid JSON", pageURL)
+	}
+
+	definition := r.definition.JSON
+	fPage := 1
+
+	if pages, ok := jsonNumber(jsonGet(raw, definition.Pages)); ok {
+		fPage = int(pages)
+	}
+
+	// Iterate products and append to matches
+	for _, item := range jsonGet(raw, definition.Products).Array() {
+		data := []byte(item.Raw)
+
+		product := Product{
+			Name:  jsonString(jsonGet(data, definition.Title)),
+			URL:   resolveURL(pageURL, jsonTemplate(data, definition.URL)),
+			SKU:   jsonString(jsonGet(data, definition.SKU)),
+			Image: resolveURL(pageURL, jsonTemplate(data, definition.Image)),
+		}
+
+		if price, ok := jsonNumber(jsonGet(data, definition.Price)); ok {
+			product.Price = price
+		}
+
+		stock := jsonGet(data, definition.Stock)
+
+		// Only a true result or the in stock value is in stock
+		if definition.InStock != "" {
+			product.InStock = stock.Exists() && strings.EqualFold(stock.String(), definition.InStock)
+		} else {
+			product.InStock = stock.Type == gjson.True
+		}
+
+		matches = append(matches, product)
+	}
+
+	return matches, fPage, nil
+}
+
+// jsonGet reads an optional path from data,
+// missing paths return an empty result
+func jsonGet(data []byte, path string) gjson.Result {
+	if path == "" {
+		return gjson.Result{}
+	}
+
+	return gjson.GetBytes(data, path)
+}
+
+// jsonTemplate reads a path, or a template with
+// paths in braces, from data as a string
+func jsonTemplate(data []byte, template string) string {
+	if !strings.Contains(template, "{") {
+		return jsonString(jsonGet(data, template))
+	}
+
+	return jsonPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return jsonString(jsonGet(data, strings.Trim(placeholder, "{}")))
+	})
+}
+
+// jsonString converts a string or number result to a string
+func jsonString(value gjson.Result) string {
+	switch value.Type {
+	case gjson.String:
+		return strings.TrimSpace(value.Str)
+	case gjson.Number:
+		return strconv.FormatFloat(value.Num, 'f', -1, 64)
+	}
+
+	return ""
+}
+
+// jsonNumber converts a number result, or a string such as
+// a formatted price, to a float
+func jsonNumber(value gjson.Result) (float64, bool) {
+	switch value.Type {
+	case gjson.Number:
+		return value.Num, true
+	case gjson.String:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(jsonPrice.FindString(value.Str), ",", ""), 64)
+
+		return f, err == nil
+	}
+
+	return 0, false
+}
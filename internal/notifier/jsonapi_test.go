@@ -0,0 +1,153 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIDefinitions = `
+- name: Test.api
+  search: "%s/api/search?q={term}&page={page}"
+  json:
+    products: results.items
+    pages: results.paging.last
+    title: name
+    url: links.self
+    sku: code
+    image: images.0
+    price: pricing.current
+    stock: availability
+    inStock: IN_STOCK
+`
+
+const testAPIResponse = `{
+	"results": {
+		"paging": {"last": "2"},
+		"items": [
+			{
+				"code": 1001,
+				"name": " RTX 3070 ",
+				"links": {"self": "/p/1001"},
+				"images": ["https://cdn.test/1001.jpg"],
+				"pricing": {"current": "£1,199.99"},
+				"availability": "IN_STOCK"
+			},
+			{
+				"code": 1002,
+				"name": "RTX 3080",
+				"pricing": {"current": 649},
+				"availability": "OUT_OF_STOCK"
+			}
+		]
+	}
+}`
+
+// TestJSONRetailerFetch ensures defined API retailers
+// are fetched and parsed with their paths
+func TestJSONRetailerFetch(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, testAPIResponse)
+	}))
+	defer server.Close()
+
+	retailers, err := ParseRetailers([]byte(fmt.Sprintf(testAPIDefinitions, server.URL)), false)
+	assert.Nil(t, err)
+	assert.IsType(t, &JSONRetailer{}, retailers[0])
+
+	c := GetTestContext()
+	c.HTTP = server.Client()
+	c.Config = &Config{}
+
+	response, err := retailers[0].Fetch(context.Background(), c, Filter{Term: "rtx"})
+
+	// Both pages return the same products
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Len(t, response.Matches, 2)
+
+	products, pages, err := retailers[0].(*JSONRetailer).parse(server.URL+"/api/search", []byte(testAPIResponse))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, pages)
+	assert.Equal(t, []Product{
+		{
+			Name:    "RTX 3070",
+			URL:     server.URL + "/p/1001",
+			SKU:     "1001",
+			Image:   "https://cdn.test/1001.jpg",
+			Price:   1199.99,
+			InStock: true,
+		},
+		{
+			Name:  "RTX 3080",
+			SKU:   "1002",
+			Price: 649,
+		},
+	}, products)
+}
+
+// TestJSONRetailerArgos ensures the built
+// in Argos definition parses its API
+func TestJSONRetailerArgos(t *testing.T) {
+	products, pages, err := argos.parse("https://www.argos.co.uk/finder-api/product", []byte(`{
+		"data": {
+			"response": {
+				"meta": {"pageSize": 30, "currentPage": 1, "totalPages": 3},
+				"data": [{
+					"id": "8349024",
+					"attributes": {"name": "RTX 3070", "price": 529.99, "reservable": false, "deliverable": true}
+				}]
+			}
+		}
+	}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []Product{{
+		Name:    "RTX 3070",
+		URL:     "https://www.argos.co.uk/product/8349024",
+		SKU:     "8349024",
+		Image:   "https://media.4rgos.it/s/Argos/8349024_R_SET",
+		Price:   529.99,
+		InStock: true,
+	}}, products)
+
+	_, _, err = argos.parse("https://www.argos.co.uk/finder-api/product", []byte("<html>"))
+	assert.NotNil(t, err)
+}
+
+// TestJSONTemplate ensures templates fill
+// each path and plain paths are read
+func TestJSONTemplate(t *testing.T) {
+	data := []byte(`{"id": 1001, "slug": "rtx-3070", "links": {"self": "/p/1001"}}`)
+
+	assert.Equal(t, "/p/1001", jsonTemplate(data, "links.self"))
+	assert.Equal(t, "/product/1001/rtx-3070", jsonTemplate(data, "/product/{id}/{slug}"))
+	assert.Equal(t, "/product/", jsonTemplate(data, "/product/{missing}"))
+	assert.Empty(t, jsonTemplate(data, ""))
+}
+
+// TestNewJSONRetailerInvalid ensures invalid
+// definitions are rejected
+func TestNewJSONRetailerInvalid(t *testing.T) {
+	tests := []RetailerDefinition{
+		{Name: "Test.api", Search: "https://test.api/search", JSON: &JSONDefinition{Products: "items", Title: "name"}},
+		{Name: "Test.api", Search: "https://test.api/search?q={term}", JSON: &JSONDefinition{Title: "name"}},
+		{Name: "Test.api", Search: "https://test.api/search?q={term}", JSON: &JSONDefinition{Products: "items", Title: "name", URL: "/p/{id"}},
+		{Name: "Test.api", Search: "https://test.api/search?q={term}"},
+	}
+
+	for _, definition := range tests {
+		_, err := NewJSONRetailer(definition)
+		assert.NotNil(t, err)
+	}
+}
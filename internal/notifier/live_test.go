@@ -0,0 +1,59 @@
+//go:build live
+// +build live
+
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// liveSearches are the terms each built in
+// retailer is searched for by the canary
+var liveSearches = map[string]string{
+	"Argos.co.uk":        "Playstation 5",
+	"Currys.co.uk":       "Playstation 5",
+	"Ebuyer.com":         "RTX 3070",
+	"Novatech.co.uk":     "AMD Ryzen",
+	"Overclockers.co.uk": "RTX 3070",
+	"Scan.co.uk":         "AMD Ryzen",
+	"Very.co.uk":         "Playstation 5",
+}
+
+// TestLiveRetailers is a canary searching every built in retailer
+// live to catch blocking and selector drift, it is only built
+// with the live tag so offline runs never depend on retailers
+func TestLiveRetailers(t *testing.T) {
+	for name, term := range liveSearches {
+		name, term := name, term
+
+		t.Run(name, func(t *testing.T) {
+			r, ok := GetRetailer(name)
+
+			if !ok {
+				t.Fatalf("Retailer %s isn't registered", name)
+			}
+
+			c := GetTestContext()
+			c.Config = &Config{MaxPages: 2, PageWorkers: 1}
+
+			response, err := r.Fetch(context.Background(), c, Filter{Term: term})
+
+			assert.Nil(t, err)
+			assert.NotEmpty(t, response.Matches)
+
+			// At least one product should be fully parsed
+			parsed := false
+
+			for _, product := range response.Matches {
+				if product.Name != "" && product.Price > 0 && product.URL != "" {
+					parsed = true
+				}
+			}
+
+			assert.True(t, parsed, "No product from %s had a name, price and URL", name)
+		})
+	}
+}
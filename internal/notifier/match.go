@@ -0,0 +1,423 @@
+package notifier
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// expression defines a node of a parsed
+// boolean matching expression
+type expression interface {
+	eval(name string) bool
+}
+
+// termExpression matches when the name contains
+// the term as whole words, ignoring case
+type termExpression string
+
+// notExpression negates an expression
+type notExpression struct {
+	e expression
+}
+
+// andExpression matches when both
+// expressions match
+type andExpression struct {
+	l, r expression
+}
+
+// orExpression matches when either
+// expression matches
+type orExpression struct {
+	l, r expression
+}
+
+// matcher is the compiled form of the
+// matching rules on a filter
+type matcher struct {
+	term       []string
+	synonyms   []synonym
+	expression expression
+	include    []string
+	exclude    []string
+	patterns   []*regexp.Regexp
+}
+
+// expressionParser is a recursive descent parser
+// for boolean matching expressions
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (t termExpression) eval(name string) bool {
+	return containsWords(name, string(t))
+}
+
+func (n notExpression) eval(name string) bool {
+	return !n.e.eval(name)
+}
+
+func (a andExpression) eval(name string) bool {
+	return a.l.eval(name) && a.r.eval(name)
+}
+
+func (o orExpression) eval(name string) bool {
+	return o.l.eval(name) || o.r.eval(name)
+}
+
+// Compile validates and compiles the matching rules
+// on the filter, this is called when filters are decoded
+func (f *Filter) Compile() error {
+	m, err := f.compile()
+
+	if err != nil {
+		return err
+	}
+
+	f.matcher = m
+
+	return nil
+}
+
+// compile builds the matcher for the filter
+func (f *Filter) compile() (*matcher, error) {
+	m := &matcher{}
+
+	// The term labels the filter so default
+	// it when only a match or query is set
+	if f.Term == "" {
+		f.Term = f.MatchTerm()
+	}
+
+	if f.Term == "" && len(f.Query) > 0 {
+		f.Term = f.Query[0]
+	}
+
+	if f.Fuzzy < 0 || f.Fuzzy > 1 {
+		return nil, fmt.Errorf("Invalid fuzzy threshold for filter %s, must be between 0 and 1", f.Term)
+	}
+
+	// Normalise the term for token and fuzzy matching
+	m.synonyms = compileSynonyms(f.Synonyms)
+	m.term = applySynonyms(Normalise(f.MatchTerm()), m.synonyms)
+
+	if f.Expression != "" {
+		e, err := ParseExpression(f.Expression)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid expression for filter %s, error: %v", f.Term, err)
+		}
+
+		m.expression = e
+	}
+
+	for _, keyword := range f.Include {
+		m.include = append(m.include, strings.ToLower(keyword))
+	}
+
+	for _, keyword := range f.Exclude {
+		m.exclude = append(m.exclude, strings.ToLower(keyword))
+	}
+
+	for _, pattern := range f.Patterns {
+		re, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern for filter %s, error: %v", f.Term, err)
+		}
+
+		m.patterns = append(m.patterns, re)
+	}
+
+	return m, nil
+}
+
+// Matches checks whether a product name matches the filter, the
+// expression replaces the match term when set and include,
+// exclude and patterns must all be satisfied
+func (f Filter) Matches(name string) bool {
+	m := f.matcher
+
+	// Compile filters that weren't decoded, any
+	// errors would have been surfaced by Compile
+	if m == nil {
+		var err error
+
+		m, err = f.compile()
+
+		if err != nil {
+			return false
+		}
+	}
+
+	lower := strings.ToLower(name)
+
+	if m.expression != nil {
+		if !m.expression.eval(lower) {
+			return false
+		}
+	} else if !f.matchTerm(m, name) {
+		return false
+	}
+
+	for _, keyword := range m.include {
+		if !containsWords(lower, keyword) {
+			return false
+		}
+	}
+
+	for _, keyword := range m.exclude {
+		if containsWords(lower, keyword) {
+			return false
+		}
+	}
+
+	for _, re := range m.patterns {
+		if !re.MatchString(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchTerm checks the name contains the match term,
+// either directly, by normalised tokens or fuzzily
+func (f Filter) matchTerm(m *matcher, name string) bool {
+	switch {
+	case f.Fuzzy > 0:
+		return fuzzyMatch(m.term, applySynonyms(Normalise(name), m.synonyms), f.Fuzzy)
+	case f.Tokens:
+		return tokenMatch(m.term, applySynonyms(Normalise(name), m.synonyms))
+	}
+
+	return strings.Contains(strings.ToLower(name), strings.ToLower(f.MatchTerm()))
+}
+
+// containsWords checks whether s contains the words on word
+// boundaries, so "ti" doesn't match inside "edition"
+func containsWords(s, words string) bool {
+	if words == "" {
+		return true
+	}
+
+	first, _ := utf8.DecodeRuneInString(words)
+	last, _ := utf8.DecodeLastRuneInString(words)
+
+	for offset := 0; offset < len(s); {
+		i := strings.Index(s[offset:], words)
+
+		if i < 0 {
+			return false
+		}
+
+		start := offset + i
+		end := start + len(words)
+
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+
+		if !(start > 0 && joined(before, first)) && !(end < len(s) && joined(last, after)) {
+			return true
+		}
+
+		_, size := utf8.DecodeRuneInString(s[start:])
+		offset = start + size
+	}
+
+	return false
+}
+
+// joined checks whether two adjacent runes
+// are part of the same word
+func joined(a, b rune) bool {
+	return (unicode.IsLetter(a) || unicode.IsDigit(a)) && (unicode.IsLetter(b) || unicode.IsDigit(b))
+}
+
+// ParseExpression parses a boolean matching expression such as
+// `"RTX 3080" AND NOT (Ti OR laptop)`, adjacent terms are
+// implicitly joined with AND and terms match ignoring case
+func ParseExpression(s string) (expression, error) {
+	tokens, err := tokenizeExpression(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("Empty expression")
+	}
+
+	p := &expressionParser{tokens: tokens}
+	e, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %s", p.tokens[p.pos])
+	}
+
+	return e, nil
+}
+
+// tokenizeExpression splits an expression into quoted
+// terms, words and parentheses
+func tokenizeExpression(s string) ([]string, error) {
+	var tokens []string
+
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+		case r == '"':
+			end := i + 1
+
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+
+			if end == len(runes) {
+				return nil, fmt.Errorf("Unterminated quote")
+			}
+
+			// Keep the quotes so quoted operators are terms
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end
+		default:
+			end := i
+
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+
+			tokens = append(tokens, string(runes[i:end]))
+			i = end - 1
+		}
+	}
+
+	return tokens, nil
+}
+
+// peek returns the next token without consuming it
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+// parseOr parses expressions joined by OR
+func (p *expressionParser) parseOr() (expression, error) {
+	l, err := p.parseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "OR" {
+		p.pos++
+
+		r, err := p.parseAnd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		l = orExpression{l: l, r: r}
+	}
+
+	return l, nil
+}
+
+// parseAnd parses expressions joined by AND
+// or implicitly by being adjacent
+func (p *expressionParser) parseAnd() (expression, error) {
+	l, err := p.parseNot()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		next := p.peek()
+
+		if next == "" || next == "OR" || next == ")" {
+			return l, nil
+		}
+
+		if next == "AND" {
+			p.pos++
+		}
+
+		r, err := p.parseNot()
+
+		if err != nil {
+			return nil, err
+		}
+
+		l = andExpression{l: l, r: r}
+	}
+}
+
+// parseNot parses a negated expression
+func (p *expressionParser) parseNot() (expression, error) {
+	if p.peek() == "NOT" {
+		p.pos++
+
+		e, err := p.parseNot()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpression{e: e}, nil
+	}
+
+	return p.parseTerm()
+}
+
+// parseTerm parses a term or a
+// parenthesised expression
+func (p *expressionParser) parseTerm() (expression, error) {
+	token := p.peek()
+
+	switch token {
+	case "":
+		return nil, fmt.Errorf("Unexpected end of expression")
+	case "AND", "OR", ")":
+		return nil, fmt.Errorf("Unexpected %s", token)
+	case "(":
+		p.pos++
+
+		e, err := p.parseOr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("Missing closing parenthesis")
+		}
+
+		p.pos++
+
+		return e, nil
+	}
+
+	p.pos++
+
+	return termExpression(strings.ToLower(strings.Trim(token, `"`))), nil
+}
@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseExpression tests evaluating
+// boolean matching expressions
+func TestParseExpression(t *testing.T) {
+	e, err := ParseExpression(`"RTX 3080" AND NOT (Ti OR laptop)`)
+	assert.Nil(t, err)
+
+	assert.True(t, e.eval("msi geforce rtx 3080 gaming x trio"))
+	assert.False(t, e.eval("msi geforce rtx 3080 ti gaming x trio"))
+	assert.False(t, e.eval("asus rog rtx 3080 gaming laptop"))
+	assert.False(t, e.eval("rtx 3070"))
+
+	// Adjacent terms are joined with AND
+	e, err = ParseExpression(`playstation 5 OR ps5`)
+	assert.Nil(t, err)
+
+	assert.True(t, e.eval("sony playstation 5 console"))
+	assert.True(t, e.eval("ps5 digital edition"))
+	assert.False(t, e.eval("playstation 4"))
+
+	// Quoted operators are terms
+	e, err = ParseExpression(`"OR"`)
+	assert.Nil(t, err)
+	assert.True(t, e.eval("or"))
+
+	// Invalid expressions
+	for _, invalid := range []string{"", "(rtx", "rtx)", "AND rtx", "rtx OR", `"rtx`, "NOT"} {
+		_, err = ParseExpression(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+// TestContainsWords tests words are
+// only matched on word boundaries
+func TestContainsWords(t *testing.T) {
+	assert.True(t, containsWords("rtx 3080 ti", "ti"))
+	assert.True(t, containsWords("rtx 3080-ti", "ti"))
+	assert.True(t, containsWords("rtx 3080 ti", "3080 ti"))
+	assert.True(t, containsWords("edition ti", "ti"))
+	assert.True(t, containsWords("4k+ monitor", "4k+"))
+	assert.False(t, containsWords("founders edition", "ti"))
+	assert.False(t, containsWords("rtx 30800", "3080"))
+	assert.False(t, containsWords("rtx 3080ti", "ti"))
+}
+
+// TestFilterMatches tests each of the
+// filter matching rules
+func TestFilterMatches(t *testing.T) {
+	filter := new(FilterDecoder)
+	err := filter.Decode(`[
+		{"term": "RTX 3080", "interval": 60, "exclude": ["Ti", "Laptop"]},
+		{"term": "3080", "interval": 60, "expression": "\"RTX 3080\" AND NOT (Ti OR laptop)", "include": ["gaming"]},
+		{"term": "RTX", "interval": 60, "patterns": ["RTX 30[78]0\\b"]}
+	]`)
+	assert.Nil(t, err)
+
+	exclude := (*filter)[0]
+	assert.True(t, exclude.Matches("MSI RTX 3080 Gaming"))
+	assert.False(t, exclude.Matches("MSI RTX 3080 Ti Gaming"))
+	assert.False(t, exclude.Matches("RTX 3080 laptop"))
+	assert.True(t, exclude.Matches("RTX 3080 Founders Edition"))
+
+	expression := (*filter)[1]
+	assert.True(t, expression.Matches("MSI RTX 3080 Gaming"))
+	assert.False(t, expression.Matches("MSI RTX 3080"))
+	assert.False(t, expression.Matches("MSI RTX 3080 Ti Gaming"))
+	assert.False(t, expression.Matches("MSI RTX 30800 Gaming"))
+
+	// Terms match whole words, not parts of words
+	founders, err := ParseExpression(`"RTX 3080" AND NOT (Ti OR laptop)`)
+	assert.Nil(t, err)
+	assert.True(t, founders.eval("rtx 3080 founders edition"))
+	assert.False(t, founders.eval("rtx 3080 ti founders edition"))
+
+	patterns := (*filter)[2]
+	assert.True(t, patterns.Matches("RTX 3070 FE"))
+	assert.False(t, patterns.Matches("RTX 3090 FE"))
+	assert.False(t, patterns.Matches("RTX 30800"))
+
+	// Filters that weren't decoded still match
+	assert.True(t, Filter{Term: "rtx", Exclude: []string{"ti"}}.Matches("RTX 3080"))
+
+	// Invalid rules are surfaced when decoding
+	err = filter.Decode(`[{"term": "test", "patterns": ["("]}]`)
+	assert.NotNil(t, err)
+
+	err = filter.Decode(`[{"term": "test", "expression": "(test"}]`)
+	assert.NotNil(t, err)
+}
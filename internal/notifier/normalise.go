@@ -0,0 +1,235 @@
+package notifier
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// synonym maps an alias to the
+// canonical tokens it is replaced by
+type synonym struct {
+	alias     []string
+	canonical []string
+}
+
+// Normalise lowercases the name, strips punctuation and splits
+// letters from digits so "GeForce RTX3070-OC" and "geforce
+// rtx 3070 oc" both become the same tokens
+func Normalise(name string) []string {
+	var b strings.Builder
+
+	var previous rune
+
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// Split at letter and digit boundaries
+			if previous != 0 && unicode.IsDigit(r) != unicode.IsDigit(previous) {
+				b.WriteRune(' ')
+			}
+
+			b.WriteRune(r)
+			previous = r
+		default:
+			b.WriteRune(' ')
+			previous = 0
+		}
+	}
+
+	return strings.Fields(b.String())
+}
+
+// compileSynonyms normalises the synonyms so they
+// can be applied to normalised tokens
+func compileSynonyms(synonyms map[string][]string) []synonym {
+	var compiled []synonym
+
+	for canonical, aliases := range synonyms {
+		for _, alias := range aliases {
+			compiled = append(compiled, synonym{
+				alias:     Normalise(alias),
+				canonical: Normalise(canonical),
+			})
+		}
+	}
+
+	// Apply longer aliases first so they
+	// aren't broken up by shorter ones
+	sort.Slice(compiled, func(i, j int) bool {
+		if len(compiled[i].alias) != len(compiled[j].alias) {
+			return len(compiled[i].alias) > len(compiled[j].alias)
+		}
+
+		return strings.Join(compiled[i].alias, " ") < strings.Join(compiled[j].alias, " ")
+	})
+
+	return compiled
+}
+
+// applySynonyms replaces every alias in
+// the tokens with its canonical tokens
+func applySynonyms(tokens []string, synonyms []synonym) []string {
+	for _, s := range synonyms {
+		if len(s.alias) == 0 {
+			continue
+		}
+
+		var replaced []string
+
+		for i := 0; i < len(tokens); i++ {
+			if hasTokens(tokens[i:], s.alias) {
+				replaced = append(replaced, s.canonical...)
+				i += len(s.alias) - 1
+				continue
+			}
+
+			replaced = append(replaced, tokens[i])
+		}
+
+		tokens = replaced
+	}
+
+	return tokens
+}
+
+// hasTokens checks whether tokens starts with prefix
+func hasTokens(tokens, prefix []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+
+	for i := range prefix {
+		if tokens[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tokenMatch checks whether every term
+// token is present in the name tokens
+func tokenMatch(term, name []string) bool {
+	present := map[string]bool{}
+
+	for _, token := range name {
+		present[token] = true
+	}
+
+	for _, token := range term {
+		if !present[token] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fuzzyMatch scores how closely the name tokens match the term
+// tokens and checks the score meets the threshold, numbers must
+// match exactly so "3070" never matches "3080"
+func fuzzyMatch(term, name []string, threshold float64) bool {
+	if len(term) == 0 {
+		return true
+	}
+
+	var total float64
+
+	for _, t := range term {
+		var best float64
+
+		for _, n := range name {
+			if isNumber(t) || isNumber(n) {
+				if t == n {
+					best = 1
+				}
+
+				continue
+			}
+
+			if score := similarity(t, n); score > best {
+				best = score
+			}
+		}
+
+		// A missing number can never match
+		if isNumber(t) && best < 1 {
+			return false
+		}
+
+		total += best
+	}
+
+	return total/float64(len(term)) >= threshold
+}
+
+// similarity returns the normalised levenshtein
+// similarity of two strings between 0 and 1
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := len(ra)
+
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minimum(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// isNumber checks whether the token is all digits
+func isNumber(token string) bool {
+	for _, r := range token {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return token != ""
+}
+
+// minimum returns the smallest of the values
+func minimum(values ...int) int {
+	m := values[0]
+
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalise tests product names are
+// normalised into comparable tokens
+func TestNormalise(t *testing.T) {
+	assert.Equal(t, []string{"geforce", "rtx", "3070"}, Normalise("GeForce RTX3070"))
+	assert.Equal(t, []string{"rtx", "3070"}, Normalise("RTX-3070"))
+	assert.Equal(t, []string{"rtx", "3070", "8", "gb"}, Normalise("rtx 3070 8GB"))
+	assert.Equal(t, []string{"asus", "tuf", "rtx", "3070", "oc"}, Normalise("ASUS  TUF, RTX™ 3070 (OC)"))
+	assert.Empty(t, Normalise(" - "))
+}
+
+// TestTokenMatching tests token based matching
+// against real world name variants
+func TestTokenMatching(t *testing.T) {
+	filter := Filter{Term: "RTX 3070", Tokens: true}
+
+	for _, name := range []string{
+		"Gigabyte GeForce RTX3070 Gaming OC 8GB",
+		"MSI RTX-3070 Ventus 2X",
+		"rtx 3070 8GB",
+		"ASUS TUF Gaming GeForce RTX™ 3070 OC",
+		"NVIDIA GeForce RTX 3070 Founders Edition",
+		"Zotac 3070 RTX Twin Edge",
+	} {
+		assert.True(t, filter.Matches(name), name)
+	}
+
+	for _, name := range []string{
+		"Gigabyte GeForce RTX3080 Gaming OC",
+		"RTX 30700",
+		"GTX 1070",
+	} {
+		assert.False(t, filter.Matches(name), name)
+	}
+
+	// The plain substring match misses variants
+	assert.False(t, Filter{Term: "RTX 3070"}.Matches("GeForce RTX3070"))
+}
+
+// TestSynonyms tests configured synonyms
+// are applied to the term and names
+func TestSynonyms(t *testing.T) {
+	filter := Filter{
+		Term:   "PS5",
+		Tokens: true,
+		Synonyms: map[string][]string{
+			"playstation 5": {"ps5", "ps 5"},
+		},
+	}
+
+	assert.True(t, filter.Matches("Sony PlayStation 5 Console"))
+	assert.True(t, filter.Matches("PS5 Digital Edition"))
+	assert.True(t, filter.Matches("Playstation5 Console"))
+	assert.False(t, filter.Matches("PlayStation 4 Pro"))
+}
+
+// TestFuzzyMatching tests fuzzy matching
+// tolerates typos but not model numbers
+func TestFuzzyMatching(t *testing.T) {
+	filter := new(FilterDecoder)
+	err := filter.Decode(`[{"term": "GeForce RTX 3070", "interval": 60, "fuzzy": 0.8}]`)
+	assert.Nil(t, err)
+
+	fuzzy := (*filter)[0]
+	assert.True(t, fuzzy.Matches("Geforse RTX3070 Gaming"))
+	assert.True(t, fuzzy.Matches("GeForce RTX 3070"))
+	assert.False(t, fuzzy.Matches("GeForce RTX 3080"))
+	assert.False(t, fuzzy.Matches("Radeon RX 3070"))
+
+	assert.Equal(t, float64(1), similarity("rtx", "rtx"))
+	assert.Equal(t, 0.75, similarity("rtxs", "rtx"))
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+
+	// Thresholds must be a ratio
+	err = filter.Decode(`[{"term": "test", "fuzzy": 1.5}]`)
+	assert.NotNil(t, err)
+}
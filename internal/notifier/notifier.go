@@ -2,22 +2,21 @@ package notifier
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/alexlast/stock-notifier/internal/metrics"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ses"
 	"github.com/aws/aws-sdk-go/service/ses/sesiface"
-	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
-	"github.com/jasonlvhit/gocron"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
@@ -25,19 +24,40 @@ import (
 // Filter defines the configuration
 // for a search filter
 type Filter struct {
-	Term     string  `json:"term"`
-	MinPrice float64 `json:"minPrice"`
-	MaxPrice float64 `json:"maxPrice"`
-	Interval int64   `json:"interval"`
+	Term             string                `json:"term"`
+	Query            StringList            `json:"query"`
+	Match            string                `json:"match"`
+	RetailerQueries  map[string]StringList `json:"retailerQueries"`
+	MinPrice         float64               `json:"minPrice"`
+	MaxPrice         float64               `json:"maxPrice"`
+	Interval         int64                 `json:"interval"`
+	Cron             string                `json:"schedule"`
+	Hours            string                `json:"activeHours"`
+	Retailers        []string              `json:"retailers"`
+	ExcludeRetailers []string              `json:"excludeRetailers"`
+	DropPercent      float64               `json:"dropPercent"`
+	DropAmount       float64               `json:"dropAmount"`
+	LowestPrice      bool                  `json:"lowestPrice"`
+	Include          []string              `json:"include"`
+	Exclude          []string              `json:"exclude"`
+	Patterns         []string              `json:"patterns"`
+	Expression       string                `json:"expression"`
+	Tokens           bool                  `json:"tokens"`
+	Fuzzy            float64               `json:"fuzzy"`
+	Synonyms         map[string][]string   `json:"synonyms"`
+	matcher          *matcher
 }
 
 // Product defines the structure
 // for any product returned by
 // any retailer
 type Product struct {
-	Name    string
-	Price   float64
-	InStock bool
+	Name    string  `json:"name"`
+	Price   float64 `json:"price"`
+	InStock bool    `json:"inStock"`
+	URL     string  `json:"url,omitempty"`
+	SKU     string  `json:"sku,omitempty"`
+	Image   string  `json:"image,omitempty"`
 }
 
 // Response defines the structure
@@ -55,8 +75,12 @@ type FilterDecoder []Filter
 // Notify defines the configuration
 // for who should be notified
 type Notify struct {
-	Email *string `json:"email"`
-	Phone *string `json:"phone"`
+	Email    *string   `json:"email"`
+	Phone    *string   `json:"phone"`
+	Webhook  *Webhook  `json:"webhook"`
+	Discord  *string   `json:"discord"`
+	Slack    *string   `json:"slack"`
+	Telegram *Telegram `json:"telegram"`
 }
 
 // NotifyDecoder is a type
@@ -66,90 +90,184 @@ type NotifyDecoder []Notify
 // Config defines the configuration
 // for notifier
 type Config struct {
-	Notify      NotifyDecoder `required:"true"`
-	Filters     FilterDecoder `required:"true"`
-	CacheTTL    int           `required:"true" split_words:"true"`
-	LogLevel    string        `split_words:"true"`
-	AWSRegion   string        `required:"true" envconfig:"AWS_REGION"`
-	FromAddress string        `required:"true" split_words:"true"`
+	Notify        NotifyDecoder `required:"true"`
+	Filters       FilterDecoder `required:"true"`
+	CacheTTL      int           `required:"true" split_words:"true"`
+	CacheBackend  string        `default:"memory" split_words:"true"`
+	CacheFile     string        `default:"notifications.json" split_words:"true"`
+	RedisURL      string        `split_words:"true"`
+	AlertMode     string        `default:"ttl" split_words:"true"`
+	SoldOutAlerts bool          `split_words:"true"`
+	HistoryFile   string        `split_words:"true"`
+	StateFile     string        `split_words:"true"`
+	MaxPages      int           `default:"10" split_words:"true"`
+	PageWorkers   int           `default:"3" split_words:"true"`
+	RateLimit     float64       `default:"1" split_words:"true"`
+	RateBurst     int           `default:"2" split_words:"true"`
+	PollJitter    int           `default:"5" split_words:"true"`
+	ActiveHours   HoursDecoder  `split_words:"true"`
+	LogLevel      string        `split_words:"true"`
+	AWSRegion     string        `required:"true" envconfig:"AWS_REGION"`
+	FromAddress   string        `required:"true" split_words:"true"`
+	TelegramURL   string        `split_words:"true"`
+
+	RetailerRateLimits RateLimitDecoder `split_words:"true"`
+	Retries            int              `default:"2" split_words:"true"`
+	RetryBackoff       int              `default:"1000" split_words:"true"`
+	RetryMaxBackoff    int              `default:"30000" split_words:"true"`
+	BreakerThreshold   int              `default:"5" split_words:"true"`
+	BreakerCooldown    int              `default:"300" split_words:"true"`
+	ShutdownTimeout    int              `default:"30" split_words:"true"`
+	Proxies            []string         `split_words:"true"`
+	ProxyRotation      string           `default:"request" split_words:"true"`
+	ProxyFailures      int              `default:"3" split_words:"true"`
+	ProxyQuarantine    int              `default:"300" split_words:"true"`
+	RetailerFile       string           `split_words:"true"`
+	AdminNotify        NotifyDecoder    `split_words:"true"`
+	ParserThreshold    float64          `default:"0.5" split_words:"true"`
 }
 
 // Context defines the notifier
 // context
 type Context struct {
-	SES    sesiface.SESAPI
-	SNS    snsiface.SNSAPI
-	HTTP   *http.Client
-	Config *Config
+	SES      sesiface.SESAPI
+	SNS      snsiface.SNSAPI
+	HTTP     *http.Client
+	Direct   *http.Client
+	Cache    Cache
+	State    *StateTracker
+	History  *PriceHistory
+	Limiter  *HostLimiter
+	Breakers *Breakers
+	Health   *ParserHealth
+	Status   *StatusTracker
+	Config   *Config
+
+	polls pollGroup
 }
 
 const (
 	smsFromName    = "Stock"
 	smsFormat      = "The following products were found on %s: \n\n%s"
 	cacheKeyFormat = "%s:%s:%f:%s"
+	priceFormat    = "£%.2f"
 )
 
-// notificationCache is a simple cache
-// of sent notifications with a TTL
-var notificationCache = map[string]time.Time{}
-
-// Start will start all polling jobs
-// for retailers
-func (c *Context) Start() {
+// Start will start all polling jobs for retailers and
+// block until ctx is cancelled, running polls are left
+// to be drained by Shutdown, an invalid filter or no
+// jobs to run returns an error before polling starts
+func (c *Context) Start(ctx context.Context) error {
 	log.Infoln("Polling retailers")
 
+	// Unknown alert modes fall back to ttl
+	if c.Config.AlertMode != alertModeTTL && c.Config.AlertMode != alertModeTransition {
+		log.Warnf("Unknown alert mode %s, falling back to %s", c.Config.AlertMode, alertModeTTL)
+	}
+
+	// Remove expired notifications
+	go c.sweepCache(ctx)
+
+	scheduler := NewScheduler(time.Duration(c.Config.PollJitter) * time.Second)
+	jobs := 0
+
 	// Start polling for all filters
-	// against all retailers
+	// against all registered retailers
 	for _, filter := range c.Config.Filters {
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Ebuyer.com", filter)
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Overclockers.co.uk", filter)
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Novatech.co.uk", filter)
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Scan.co.uk", filter)
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Argos.co.uk", filter)
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Very.co.uk", filter)
-		gocron.Every(uint64(filter.Interval)).Seconds().Do(c.PollRetailer, "Currys.co.uk", filter)
+		schedule, err := filter.Schedule()
+
+		if err != nil {
+			return err
+		}
+
+		hours, err := filter.ActiveHours(c.Config)
+
+		if err != nil {
+			return err
+		}
+
+		// Warn about selected retailers that aren't registered
+		for _, names := range [][]string{filter.Retailers, filter.ExcludeRetailers} {
+			for _, name := range names {
+				if _, ok := GetRetailer(name); !ok {
+					log.Warnf("Filter %s references unknown retailer %s", filter.Term, name)
+				}
+			}
+		}
+
+		for _, retailer := range Retailers() {
+			// Skip retailers not selected by the filter
+			if !filter.PollsRetailer(retailer.Name()) {
+				continue
+			}
+
+			retailer, filter := retailer, filter
+			name := fmt.Sprintf("poll of %s for %s", retailer.Name(), filter.Term)
+
+			scheduler.Add(name, retailer.Name(), schedule, hours, func() {
+				c.runPoll(retailer, filter)
+			})
+
+			jobs++
+		}
 	}
 
-	<-gocron.Start()
+	if jobs == 0 {
+		return fmt.Errorf("No filters poll any registered retailers")
+	}
+
+	// Run until we're cancelled
+	scheduler.Run(ctx)
+
+	log.Infoln("Stopped polling retailers")
+
+	return nil
 }
 
 // PollRetailer is the wrapper for polling a retailer
 // including the sleep interval and notification trigger
-func (c *Context) PollRetailer(retailer string, filter Filter) {
-	log.Debugf("Polling %s for %s", retailer, filter.Term)
-
-	// Slice of matched products
-	var err error
-	var response Response
-
-	// Check the retailers for stock
-	switch retailer {
-	case "Ebuyer.com":
-		response, err = c.FetchEbuyer(filter, &[]Product{}, 1, 1)
-	case "Overclockers.co.uk":
-		response, err = c.FetchOverclockers(filter, &[]Product{}, 1, 1)
-	case "Novatech.co.uk":
-		response, err = c.FetchNovatech(filter, &[]Product{}, 1, 1)
-	case "Scan.co.uk":
-		response, err = c.FetchScan(filter)
-	case "Argos.co.uk":
-		response, err = c.FetchArgos(filter, &[]Product{}, 1, 1)
-	case "Very.co.uk":
-		response, err = c.FetchVery(filter, &[]Product{}, 1, 1)
-	case "Currys.co.uk":
-		response, err = c.FetchCurrys(filter, &[]Product{}, 1, 1)
+func (c *Context) PollRetailer(ctx context.Context, retailer Retailer, filter Filter) {
+	name := retailer.Name()
+
+	// Skip retailers paused by their circuit breaker
+	if !c.Breakers.Allow(name) {
+		log.Debugf("Skipping poll of %s for %s, circuit breaker is open", name, filter.Term)
+		return
+	}
+
+	log.Debugf("Polling %s for %s", name, filter.Term)
+
+	// Check the retailer for stock
+	start := time.Now()
+	response, err := c.fetchQueries(ctx, retailer, filter)
+
+	// Cancelled polls aren't the retailer's fault
+	if err != nil && ctx.Err() != nil {
+		log.Debugf("Poll of %s for %s cancelled", name, filter.Term)
+		return
 	}
 
 	if err != nil {
 		log.Errorln(err)
+		c.Breakers.Failure(name)
+		c.recordPoll(name, filter, start, Response{}, "", err)
 
 		// Increment the failed counter
 		metrics.FailedFetches.With(
-			prometheus.Labels{"retailer": retailer}).Inc()
+			prometheus.Labels{"retailer": name}).Inc()
 
 		return
 	}
 
+	c.Breakers.Success(name)
+
+	// Check the parser still understands the retailer
+	problem := c.checkParser(ctx, name, filter, response.Matches)
+
+	// Keep every product matching the term
+	// so we can track stock transitions
+	products := MatchProducts(response.Matches, filter)
+
 	// Set parsed count and
 	// perform generic filtering
 	response.Parsed = len(response.Matches)
@@ -157,21 +275,97 @@ func (c *Context) PollRetailer(retailer string, filter Filter) {
 
 	// Increment our success counters
 	metrics.SuccessfulFetches.With(
-		prometheus.Labels{"retailer": retailer}).Inc()
+		prometheus.Labels{"retailer": name}).Inc()
 	metrics.ParsedProducts.With(
-		prometheus.Labels{"retailer": retailer}).Add(float64(response.Parsed))
+		prometheus.Labels{"retailer": name}).Add(float64(response.Parsed))
 
 	// Log some useful information
-	log.Debugf("Poll of %s for %s parsed %d products, %d matched the filter", retailer, filter.Term, response.Parsed, len(response.Matches))
+	log.Debugf("Poll of %s for %s parsed %d products, %d matched the filter", name, filter.Term, response.Parsed, len(response.Matches))
+	c.recordPoll(name, filter, start, response, problem, nil)
+
+	// Results from a degraded parser would raise false
+	// sold out and price drop alerts, but products it
+	// still finds in stock are alerted
+	if problem != "" {
+		c.notifyDegraded(ctx, name, filter, products, response.Matches)
+		return
+	}
+
+	c.Status.RecordMatches(name, filter, response.Matches)
 
 	// If we matched some products, log them
 	for _, product := range response.Matches {
-		log.Infof("Retailer %s has stock for %s, product: %s", retailer, filter.Term, product.Name)
+		log.Infof("Retailer %s has stock for %s, product: %s", name, filter.Term, product.Name)
+	}
+
+	// Record prices and check for any drops
+	drops, err := c.History.Record(name, filter, products)
+
+	if err != nil {
+		log.Errorf("Unable to record price history, error: %v", err)
+	}
+
+	// Only alert when products change state
+	if c.Config.AlertMode == alertModeTransition {
+		transitions, err := c.State.Observe(name, filter, products)
+
+		if err != nil {
+			log.Errorf("Unable to record product state, error: %v", err)
+		}
+
+		err = c.NotifyTransitions(ctx, name, filter, withDrops(transitions, drops))
+
+		if err != nil {
+			log.Errorf("Unable to send notification, error: %v", err)
+		}
+
+		return
+	}
+
+	// Send notifications, products that dropped in
+	// price only get the price drop alert
+	for _, notify := range c.Config.Notify {
+		c.markNotified(name, drops, notify)
+		err = c.SendNotification(ctx, name, filter, response.Matches, notify)
+
+		if err != nil {
+			log.Errorf("Unable to send notification, error: %v", err)
+		}
+	}
+
+	// Send price drop alerts
+	err = c.NotifyTransitions(ctx, name, filter, drops)
+
+	if err != nil {
+		log.Errorf("Unable to send notification, error: %v", err)
+	}
+}
+
+// notifyDegraded sends only the stock alerts for a poll of a degraded
+// parser, products without a price are skipped as the parser may
+// have lost them and price history is left untouched
+func (c *Context) notifyDegraded(ctx context.Context, retailer string, filter Filter, products, matches []Product) {
+	products = pricedProducts(products)
+	matches = pricedProducts(matches)
+
+	if c.Config.AlertMode == alertModeTransition {
+		transitions, err := c.State.ObserveStock(retailer, filter, products)
+
+		if err != nil {
+			log.Errorf("Unable to record product state, error: %v", err)
+		}
+
+		err = c.NotifyTransitions(ctx, retailer, filter, transitions)
+
+		if err != nil {
+			log.Errorf("Unable to send notification, error: %v", err)
+		}
+
+		return
 	}
 
-	// Send notifications
 	for _, notify := range c.Config.Notify {
-		err = c.SendNotification(retailer, response.Matches, notify)
+		err := c.SendNotification(ctx, retailer, filter, matches, notify)
 
 		if err != nil {
 			log.Errorf("Unable to send notification, error: %v", err)
@@ -179,6 +373,19 @@ func (c *Context) PollRetailer(retailer string, filter Filter) {
 	}
 }
 
+// pricedProducts returns the products with a price
+func pricedProducts(p []Product) []Product {
+	var priced []Product
+
+	for _, product := range p {
+		if product.Price > 0 {
+			priced = append(priced, product)
+		}
+	}
+
+	return priced
+}
+
 // Decode is a custom decoder for filters
 // required by envconfig
 func (f *FilterDecoder) Decode(value string) error {
@@ -191,6 +398,27 @@ func (f *FilterDecoder) Decode(value string) error {
 		return fmt.Errorf("Invalid filters JSON, error: %v", err)
 	}
 
+	// Compile the matching rules and validate the schedule
+	for i := range filters {
+		err = filters[i].Compile()
+
+		if err != nil {
+			return err
+		}
+
+		_, err = filters[i].Schedule()
+
+		if err != nil {
+			return err
+		}
+
+		_, err = filters[i].ActiveHours(nil)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	// Set the filter value
 	*f = filters
 
@@ -215,14 +443,39 @@ func (n *NotifyDecoder) Decode(value string) error {
 	return nil
 }
 
+// PollsRetailer checks whether a filter should poll
+// the named retailer, an empty retailers list
+// selects all retailers
+func (f Filter) PollsRetailer(name string) bool {
+	for _, r := range f.ExcludeRetailers {
+		if strings.EqualFold(r, name) {
+			return false
+		}
+	}
+
+	// No explicit selection so
+	// poll every retailer
+	if len(f.Retailers) == 0 {
+		return true
+	}
+
+	for _, r := range f.Retailers {
+		if strings.EqualFold(r, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // FilterProducts will return a slice of filtered products
 func FilterProducts(p []Product, f Filter) []Product {
 	var filtered []Product
 
-	// Ensure all product names directly contain the search filter
-	// for better match accuracy, also ensure prices match
-	for _, product := range p {
-		if strings.Contains(strings.ToLower(product.Name), strings.ToLower(f.Term)) && product.PriceMatch(f) && product.InStock {
+	// Ensure all products match the search filter
+	// and are in stock, also ensure prices match
+	for _, product := range MatchProducts(p, f) {
+		if product.PriceMatch(f) && product.InStock {
 			filtered = append(filtered, product)
 		}
 	}
@@ -230,32 +483,87 @@ func FilterProducts(p []Product, f Filter) []Product {
 	return filtered
 }
 
-// getHash returns the hash of a notification
-// so it can be cached
+// MatchProducts will return a slice of products matching
+// the filter regardless of stock or price
+func MatchProducts(p []Product, f Filter) []Product {
+	var matched []Product
+
+	// Ensure all product names match the filter
+	// rather than trusting the retailer search
+	for _, product := range p {
+		if f.Matches(product.Name) {
+			matched = append(matched, product)
+		}
+	}
+
+	return matched
+}
+
+// getHash returns the hash of a notification so it can
+// be cached, this must be stable across restarts
 func (n Notify) getHash() string {
-	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%v", n))))
+	raw, _ := json.Marshal(n)
+	return fmt.Sprintf("%x", md5.Sum(raw))
 }
 
-// getPage returns the decoded HTML ready for parsing
-func (c *Context) getPage(url string) (*goquery.Document, error) {
-	// Build a new request and assign a random user agent
-	request, err := http.NewRequest("GET", url, bytes.NewBuffer(nil))
-	request.Header.Add("User-agent", getUserAgent())
+// resolveURL resolves a possibly relative link
+// against the URL of the page it was found on
+func resolveURL(base, href string) string {
+	href = strings.TrimSpace(href)
+
+	if href == "" {
+		return ""
+	}
 
-	response, err := c.HTTP.Do(request)
+	b, err := url.Parse(base)
 
-	// We couldn't make the HTTP request
 	if err != nil {
-		return nil, fmt.Errorf("Unable to load %s, error: %v", url, err)
+		return href
 	}
 
-	defer response.Body.Close()
+	r, err := b.Parse(href)
+
+	if err != nil {
+		return href
+	}
+
+	return r.String()
+}
+
+// imageSource returns the image URL for the first image
+// in a selection, preferring lazy loaded sources
+func imageSource(base string, s *goquery.Selection) string {
+	img := s.Find("img").First()
+
+	for _, attr := range []string{"data-src", "data-original", "src"} {
+		if src, ok := img.Attr(attr); ok && src != "" {
+			return resolveURL(base, src)
+		}
+	}
+
+	return ""
+}
+
+// waitForHost blocks until the rate limit of the host
+// allows a request, a nil limiter disables limiting
+func (c *Context) waitForHost(ctx context.Context, url string) error {
+	if c.Limiter == nil {
+		return nil
+	}
+
+	return c.Limiter.Wait(ctx, url)
+}
+
+// getPage returns the decoded HTML ready for parsing
+func (c *Context) getPage(ctx context.Context, url string) (*goquery.Document, error) {
+	response, err := c.get(ctx, url)
 
-	// We got a non 200 response
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Unable to load %s, got status code %d", url, response.StatusCode)
+	if err != nil {
+		return nil, err
 	}
 
+	defer response.Body.Close()
+
 	// Decode the response body
 	body, err := goquery.NewDocumentFromReader(response.Body)
 
@@ -269,21 +577,15 @@ func (c *Context) getPage(url string) (*goquery.Document, error) {
 // getRaw returns the body of an HTTP response, this should be
 // used for interacting with an API. To get a decoded HTML document
 // you should use the getPage function instead
-func (c *Context) getRaw(url string) ([]byte, error) {
-	response, err := c.HTTP.Get(url)
+func (c *Context) getRaw(ctx context.Context, url string) ([]byte, error) {
+	response, err := c.get(ctx, url)
 
-	// We couldn't make the HTTP request
 	if err != nil {
-		return nil, fmt.Errorf("Unable to load %s, error: %v", url, err)
+		return nil, err
 	}
 
 	defer response.Body.Close()
 
-	// We got a non 200 response
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Unable to load %s, got status code %d", url, response.StatusCode)
-	}
-
 	// Read the body
 	body, err := ioutil.ReadAll(response.Body)
 
@@ -294,95 +596,143 @@ func (c *Context) getRaw(url string) ([]byte, error) {
 	return body, err
 }
 
-// BuildSNS returns the SNS publish input
-func BuildSNS(message string, phone *string) *sns.PublishInput {
-	return &sns.PublishInput{
-		Message:     aws.String(message),
-		PhoneNumber: phone,
-		MessageAttributes: map[string]*sns.MessageAttributeValue{
-			"AWS.SNS.SMS.SenderID": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(smsFromName),
-			},
-			"AWS.SNS.SMS.SMSType": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String("Transactional"),
-			},
-		},
+// notifyClient returns the client used for notifications, the
+// direct client keeps alerts and tokens away from any proxies
+func (c *Context) notifyClient() *http.Client {
+	if c.Direct != nil {
+		return c.Direct
+	}
+
+	return c.HTTP
+}
+
+// postJSON will POST a JSON body to a URL and error on
+// any non 2xx response, this should be used for sending
+// notifications to HTTP based channels. Errors only name
+// the host as webhook URLs carry their secret in the path
+func (c *Context) postJSON(ctx context.Context, link string, body []byte, headers map[string]string) error {
+	host := postHost(link)
+	request, err := http.NewRequestWithContext(ctx, "POST", link, bytes.NewBuffer(body))
+
+	if err != nil {
+		return fmt.Errorf("Unable to build request for %s, invalid URL", host)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	response, err := c.notifyClient().Do(request)
+
+	// We couldn't make the HTTP request, the
+	// URL error repeats the full URL
+	if err != nil {
+		var urlErr *url.Error
+
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return fmt.Errorf("Unable to post to %s, error: %v", host, err)
 	}
+
+	defer response.Body.Close()
+
+	// Accept any 2xx response
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("Unable to post to %s, got status code %d", host, response.StatusCode)
+	}
+
+	return nil
 }
 
-// BuildSES returns the SES send email input
-func BuildSES(from, message string, email *string) *ses.SendEmailInput {
-	return &ses.SendEmailInput{
-		Destination: &ses.Destination{
-			ToAddresses: []*string{email},
-		},
-		Message: &ses.Message{
-			Body: &ses.Body{
-				Text: &ses.Content{
-					Charset: aws.String("UTF-8"),
-					Data:    aws.String(message),
-				},
-			},
-			Subject: &ses.Content{
-				Charset: aws.String("UTF-8"),
-				Data:    aws.String("New alert from stock-notifier"),
-			},
-		},
-		Source: aws.String(from),
+// postHost returns the host of a notification URL
+// so it can be named without leaking the path
+func postHost(link string) string {
+	u, err := url.Parse(link)
+
+	if err != nil || u.Host == "" {
+		return "an invalid URL"
 	}
+
+	return u.Host
 }
 
 // SendNotification will send notifications
 // for the supplied matches if the notification isnt in cache
-func (c *Context) SendNotification(retailer string, matches []Product, notify Notify) error {
+func (c *Context) SendNotification(ctx context.Context, retailer string, filter Filter, matches []Product, notify Notify) error {
 	var notifications []string
+	var products []Product
+
+	ttl := time.Second * time.Duration(c.Config.CacheTTL)
 
 	// Iterate our matches and build the message
 	for _, match := range matches {
 		// Build our cache key
 		key := fmt.Sprintf(cacheKeyFormat, retailer, match.Name, match.Price, notify.getHash())
 
-		// Check whether we've already
-		// sent a notification
-		ttl, exists := notificationCache[key]
+		// Check whether we've already sent a notification, if
+		// the cache is unavailable we'd rather over notify
+		sent, exists, err := c.Cache.Get(key)
+
+		if err != nil {
+			log.Warnf("Unable to check notification cache, error: %v", err)
+		}
 
 		// Update the TTL if expired or create new cache entry
-		if (exists && time.Since(ttl) > (time.Second*time.Duration(c.Config.CacheTTL))) || !exists {
-			notifications = append(notifications, match.Name)
-			notificationCache[key] = time.Now()
+		if (exists && time.Since(sent) > ttl) || !exists {
+			notifications = append(notifications, match.describe())
+			products = append(products, match)
+
+			err = c.Cache.Set(key, time.Now(), ttl)
+
+			if err != nil {
+				log.Warnf("Unable to update notification cache, error: %v", err)
+			}
 		}
 	}
 
 	// Send notifications if we have some
 	// products to alert on
 	if len(notifications) > 0 {
-		// Build the message
-		message := fmt.Sprintf(smsFormat, retailer, strings.Join(notifications, "\n\n"))
+		alert := Alert{
+			Retailer: retailer,
+			Term:     filter.Term,
+			Products: products,
+			Message:  fmt.Sprintf(smsFormat, retailer, strings.Join(notifications, "\n\n")),
+		}
+
+		return c.sendAlert(ctx, alert, notify)
+	}
 
-		var smsErr error
-		var emailErr error
+	return nil
+}
 
-		if notify.Phone != nil {
-			// Send the SMS
-			_, smsErr = c.SNS.Publish(BuildSNS(message, notify.Phone))
-		}
+// markNotified caches the products of the transitions as
+// notified to the target so SendNotification skips them
+func (c *Context) markNotified(retailer string, transitions []Transition, notify Notify) {
+	ttl := time.Second * time.Duration(c.Config.CacheTTL)
 
-		if notify.Email != nil {
-			// Send the email
-			_, emailErr = c.SES.SendEmail(BuildSES(c.Config.FromAddress, message, notify.Email))
-		}
+	for _, transition := range transitions {
+		key := fmt.Sprintf(cacheKeyFormat, retailer, transition.Product.Name, transition.Product.Price, notify.getHash())
+		err := c.Cache.Set(key, time.Now(), ttl)
 
-		// Ensure neither of these channels errored
-		for _, err := range []error{smsErr, emailErr} {
-			if err != nil {
-				return err
-			}
+		if err != nil {
+			log.Warnf("Unable to update notification cache, error: %v", err)
 		}
 	}
+}
+
+// describe returns the text used to
+// describe a product in notifications
+func (p *Product) describe() string {
+	if p.URL == "" {
+		return p.Name
+	}
 
-	return nil
+	return fmt.Sprintf("%s\n%s", p.Name, p.URL)
 }
 
 // PriceMatch checks whether a products price
@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ses"
 	"github.com/aws/aws-sdk-go/service/ses/sesiface"
 	"github.com/aws/aws-sdk-go/service/sns"
@@ -28,17 +30,19 @@ type mockSESClient struct {
 // client to be used for testing
 type mockSNSClient struct {
 	snsiface.SNSAPI
+	PublishInput       *sns.PublishInput
 	PublishReturnValue *sns.PublishOutput
 	PublishReturnError error
 }
 
-// SendEmail mocks the AWS SES SendEmail function
-func (m *mockSESClient) SendEmail(*ses.SendEmailInput) (*ses.SendEmailOutput, error) {
+// SendEmailWithContext mocks the AWS SES SendEmailWithContext function
+func (m *mockSESClient) SendEmailWithContext(aws.Context, *ses.SendEmailInput, ...request.Option) (*ses.SendEmailOutput, error) {
 	return m.SendEmailReturnValue, m.SendEmailReturnError
 }
 
-// Publish mocks the AWS SNS Publish function
-func (m *mockSNSClient) Publish(*sns.PublishInput) (*sns.PublishOutput, error) {
+// PublishWithContext mocks the AWS SNS PublishWithContext function
+func (m *mockSNSClient) PublishWithContext(ctx aws.Context, input *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error) {
+	m.PublishInput = input
 	return m.PublishReturnValue, m.PublishReturnError
 }
 
@@ -55,6 +59,9 @@ func GetTestContext() *Context {
 		HTTP: &http.Client{
 			Timeout: (time.Second * 5),
 		},
+		Cache:   NewMemoryCache(),
+		State:   &StateTracker{scopes: map[string]map[string]productState{}},
+		History: &PriceHistory{entries: map[string][]PricePoint{}},
 	}
 }
 
@@ -122,7 +129,7 @@ func TestGetPage(t *testing.T) {
 	c.HTTP = server.Client()
 
 	// Get the page
-	page, err := c.getPage(fmt.Sprintf("%s/test", server.URL))
+	page, err := c.getPage(context.Background(), fmt.Sprintf("%s/test", server.URL))
 
 	assert.Nil(t, err)
 	assert.Equal(t, "test", page.Find("p.t").Text())
@@ -151,7 +158,7 @@ func TestGetPageBadStatus(t *testing.T) {
 	c.HTTP = server.Client()
 
 	// Get the page
-	_, err := c.getPage(fmt.Sprintf("%s/test", server.URL))
+	_, err := c.getPage(context.Background(), fmt.Sprintf("%s/test", server.URL))
 
 	assert.NotNil(t, err)
 }
@@ -162,7 +169,7 @@ func TestGetPageNoResponse(t *testing.T) {
 	c := GetTestContext()
 
 	// Get the page
-	_, err := c.getPage("http://localhost/test")
+	_, err := c.getPage(context.Background(), "http://localhost/test")
 
 	assert.NotNil(t, err)
 }
@@ -184,7 +191,7 @@ func TestSendNotification(t *testing.T) {
 	}
 
 	// Send the notificatiom
-	err := c.SendNotification("test", []Product{{Name: "test", Price: 100}}, c.Config.Notify[0])
+	err := c.SendNotification(context.Background(), "test", Filter{Term: "test"}, []Product{{Name: "test", Price: 100}}, c.Config.Notify[0])
 	assert.Nil(t, err)
 
 	// Test AWS error is surfaced
@@ -193,13 +200,81 @@ func TestSendNotification(t *testing.T) {
 	}
 
 	// Send the notification again
-	err = c.SendNotification("test", []Product{{Name: "test", Price: 100}}, c.Config.Notify[0])
+	err = c.SendNotification(context.Background(), "test", Filter{Term: "test"}, []Product{{Name: "test", Price: 100}}, c.Config.Notify[0])
 	assert.NotNil(t, err)
 
 	// With phone not set the error
 	// should no longer be surfaced
 	c.Config.Notify[0].Phone = nil
 
-	err = c.SendNotification("test", []Product{{Name: "test", Price: 100}}, c.Config.Notify[0])
+	err = c.SendNotification(context.Background(), "test", Filter{Term: "test"}, []Product{{Name: "test", Price: 100}}, c.Config.Notify[0])
 	assert.Nil(t, err)
 }
+
+// TestPollsRetailer tests per filter
+// retailer selection
+func TestPollsRetailer(t *testing.T) {
+	filter := new(FilterDecoder)
+	err := filter.Decode(`[{"term": "test", "interval": 60, "retailers": ["Argos.co.uk", "very.co.uk"]}, {"term": "test", "interval": 60, "excludeRetailers": ["Scan.co.uk"]}]`)
+
+	assert.Nil(t, err)
+	assert.Len(t, *filter, 2)
+
+	// Explicit retailer selection
+	include := (*filter)[0]
+	assert.True(t, include.PollsRetailer("Argos.co.uk"))
+	assert.True(t, include.PollsRetailer("Very.co.uk"))
+	assert.False(t, include.PollsRetailer("Scan.co.uk"))
+
+	// Excluded retailers
+	exclude := (*filter)[1]
+	assert.True(t, exclude.PollsRetailer("Argos.co.uk"))
+	assert.False(t, exclude.PollsRetailer("Scan.co.uk"))
+
+	// No selection polls everything
+	assert.True(t, Filter{}.PollsRetailer("Scan.co.uk"))
+}
+
+// TestSendNotificationLink ensures product
+// links are included in notifications
+func TestSendNotificationLink(t *testing.T) {
+	c := GetTestContext()
+	c.Config = &Config{
+		CacheTTL: 60,
+	}
+
+	sns := &mockSNSClient{}
+	c.SNS = sns
+
+	// Send the notification
+	err := c.SendNotification(context.Background(), "link", Filter{Term: "linked"}, []Product{{Name: "linked", Price: 100, URL: "https://example.com/linked"}}, Notify{Phone: aws.String("+12345678")})
+
+	assert.Nil(t, err)
+	assert.Contains(t, *sns.PublishInput.Message, "linked\nhttps://example.com/linked")
+}
+
+// TestPostJSONErrors ensures errors name the
+// host without the webhook path
+func TestPostJSONErrors(t *testing.T) {
+	c := GetTestContext()
+
+	err := c.postJSON(context.Background(), "http://127.0.0.1:1/api/webhooks/123/secret", []byte("{}"), nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "127.0.0.1:1")
+	assert.NotContains(t, err.Error(), "secret")
+
+	err = c.postJSON(context.Background(), "://bad/secret", []byte("{}"), nil)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+// TestResolveURL tests resolving product
+// links against the search page
+func TestResolveURL(t *testing.T) {
+	base := "https://www.example.com/search?q=test"
+
+	assert.Equal(t, "https://www.example.com/product/1", resolveURL(base, "/product/1"))
+	assert.Equal(t, "https://cdn.example.com/1.jpg", resolveURL(base, "//cdn.example.com/1.jpg"))
+	assert.Equal(t, "https://other.com/1", resolveURL(base, " https://other.com/1 "))
+	assert.Equal(t, "", resolveURL(base, ""))
+}
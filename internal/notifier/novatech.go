@@ -1,94 +1,28 @@
 package notifier
 
 import (
-	"fmt"
-	"net/url"
-	"regexp"
-	"strconv"
-	"strings"
-	"time"
-
-	"github.com/PuerkitoBio/goquery"
+	"context"
 )
 
-const (
-	novatechSleep  = 2
-	novatechSearch = "https://www.novatech.co.uk/search.html?search=%s&pg=%d&i=200"
-)
+// novatech is parsed with the selectors of its definition,
+// product codes are the final part of the product URL
+var novatech = mustHTMLRetailer(RetailerDefinition{
+	Name:       "Novatech.co.uk",
+	Search:     "https://www.novatech.co.uk/search.html?search={term}&pg={page}&i=200",
+	Pagination: &FieldDefinition{Selector: "div.results", Pattern: "([0-9]+) Pages"},
+	Products:   "div.search-box-results",
+	Title:      FieldDefinition{Selector: "div.search-box-title", Remove: "\n"},
+	URL:        FieldDefinition{Selector: "div.search-box-title a", Attr: "href"},
+	SKU:        FieldDefinition{Selector: "div.search-box-title a", Attr: "href", Pattern: `([^/?#.]+)(\.\w+)?([?#].*)?$`},
+	Price:      FieldDefinition{Selector: "p.newspec-price"},
+	Stock:      StockDefinition{FieldDefinition: FieldDefinition{Selector: "a.basket-button"}, Text: "View Product", Match: stockMatchContains},
+})
+
+func init() {
+	RegisterRetailer(novatech)
+}
 
 // FetchNovatech will fetch results from Novatech.co.uk for the specified filter
-func (c *Context) FetchNovatech(filter Filter, matches *[]Product, cPage, fPage int) (Response, error) {
-	response := Response{}
-
-	// Get the page contents and our goquery document
-	page, err := c.getPage(fmt.Sprintf(novatechSearch, url.QueryEscape(filter.Term), cPage))
-
-	if err != nil {
-		return response, err
-	}
-
-	// Get the pagination HTML and determine
-	// how many pages we need to parse
-	pages := regexp.MustCompile("([0-9]+) Pages")
-	count := pages.FindString(page.Find("div.results").Text())
-	count = strings.ReplaceAll(count, " Pages", "")
-
-	// Convert to int, failure will set
-	// to 0, which is fine
-	fPage, _ = strconv.Atoi(count)
-
-	// Get products on the current page and extract
-	// the fields we want to filter on
-	products := page.Find("div.search-box-results")
-	products.Each(func(i int, data *goquery.Selection) {
-		title := data.Find("div.search-box-title").Text()
-		title = strings.ReplaceAll(title, "\n", "")
-
-		// Increment parsed count
-		response.Parsed++
-
-		// Build our product
-		product := Product{
-			Name: title,
-		}
-
-		// Get the product price
-		// we need to use regex to extract the price
-		re := regexp.MustCompile("[0-9].+[0-9]")
-		price := re.FindString(data.Find("p.newspec-price").Text())
-
-		// Convert price to float
-		f, err := strconv.ParseFloat(price, 64)
-
-		if err == nil {
-			product.Price = f
-		}
-
-		// Ensure the product is in-stock
-		// and matches our filter and then append to our slice
-		if strings.Contains(data.Find("a.basket-button").Text(), "View Product") {
-			product.InStock = true
-		}
-
-		*matches = append(*matches, product)
-	})
-
-	cPage++
-
-	// If there are further pages we need to recurse
-	if cPage <= fPage {
-		// Sleep between pages for 2 seconds
-		time.Sleep(time.Duration(novatechSleep) * time.Second)
-
-		// Call this function recursively
-		_, err := c.FetchNovatech(filter, matches, cPage, fPage)
-
-		if err != nil {
-			return response, err
-		}
-	}
-
-	response.Matches = *matches
-
-	return response, nil
+func (c *Context) FetchNovatech(ctx context.Context, filter Filter) (Response, error) {
+	return novatech.Fetch(ctx, c, filter)
 }
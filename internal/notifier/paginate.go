@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxPages    = 10
+	defaultPageWorkers = 3
+)
+
+// pageFunc fetches a single page of search results returning
+// the products and the last page number found on the page
+type pageFunc func(ctx context.Context, page int) ([]Product, int, error)
+
+// maxPages returns the most pages fetched per search
+func (c *Context) maxPages() int {
+	if c.Config == nil || c.Config.MaxPages <= 0 {
+		return defaultMaxPages
+	}
+
+	return c.Config.MaxPages
+}
+
+// pageWorkers returns the number of pages
+// fetched concurrently per search
+func (c *Context) pageWorkers() int {
+	if c.Config == nil || c.Config.PageWorkers <= 0 {
+		return defaultPageWorkers
+	}
+
+	return c.Config.PageWorkers
+}
+
+// paginate fetches the first page to discover the page count, then
+// fetches the remaining pages through a bounded pool of workers and
+// merges the results in page order, any failing page fails the fetch,
+// requests are rate limited per host by getPage and getRaw
+func (c *Context) paginate(ctx context.Context, fetch pageFunc) (Response, error) {
+	first, last, err := fetch(ctx, 1)
+
+	if err != nil {
+		return Response{}, err
+	}
+
+	if last > c.maxPages() {
+		log.Debugf("Search has %d pages, only fetching the first %d", last, c.maxPages())
+		last = c.maxPages()
+	}
+
+	if last < 1 {
+		last = 1
+	}
+
+	pages := make([][]Product, last)
+	errs := make([]error, last)
+	pages[0] = first
+
+	jobs := make(chan int)
+	workers := c.pageWorkers()
+
+	if workers > last-1 {
+		workers = last - 1
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for page := range jobs {
+				// Skip the remaining pages once cancelled
+				if err := ctx.Err(); err != nil {
+					errs[page-1] = err
+					continue
+				}
+
+				// Each worker writes to its own page index
+				pages[page-1], _, errs[page-1] = fetch(ctx, page)
+			}
+		}()
+	}
+
+	for page := 2; page <= last; page++ {
+		jobs <- page
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return Response{}, err
+		}
+	}
+
+	return Response{Matches: mergeProducts(pages...)}, nil
+}
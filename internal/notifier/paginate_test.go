@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPages returns a page function serving the number of
+// pages with two products each and tracking concurrency
+func testPages(pages int, fetched *[]int, active, peak *int32) pageFunc {
+	var mu sync.Mutex
+
+	return func(ctx context.Context, page int) ([]Product, int, error) {
+		n := atomic.AddInt32(active, 1)
+		defer atomic.AddInt32(active, -1)
+
+		for {
+			p := atomic.LoadInt32(peak)
+
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+
+		mu.Lock()
+		*fetched = append(*fetched, page)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		return []Product{
+			{Name: fmt.Sprintf("Product %d-1", page)},
+			{Name: fmt.Sprintf("Product %d-2", page)},
+		}, pages, nil
+	}
+}
+
+// TestPaginate ensures every page is fetched
+// and merged in page order
+func TestPaginate(t *testing.T) {
+	var fetched []int
+	var active, peak int32
+
+	c := GetTestContext()
+	c.Config = &Config{PageWorkers: 2}
+
+	response, err := c.paginate(context.Background(), testPages(5, &fetched, &active, &peak))
+
+	assert.Nil(t, err)
+	assert.Len(t, fetched, 5)
+	assert.Len(t, response.Matches, 10)
+	assert.Equal(t, "Product 1-1", response.Matches[0].Name)
+	assert.Equal(t, "Product 5-2", response.Matches[9].Name)
+	assert.LessOrEqual(t, peak, int32(2))
+}
+
+// TestPaginateMaxPages ensures the
+// page count is capped
+func TestPaginateMaxPages(t *testing.T) {
+	var fetched []int
+	var active, peak int32
+
+	c := GetTestContext()
+	c.Config = &Config{MaxPages: 3}
+
+	response, err := c.paginate(context.Background(), testPages(20, &fetched, &active, &peak))
+
+	assert.Nil(t, err)
+	assert.Len(t, fetched, 3)
+	assert.Len(t, response.Matches, 6)
+}
+
+// TestPaginateError ensures a failing
+// page fails the fetch
+func TestPaginateError(t *testing.T) {
+	c := GetTestContext()
+
+	_, err := c.paginate(context.Background(), func(ctx context.Context, page int) ([]Product, int, error) {
+		if page == 3 {
+			return nil, 0, errors.New("Some page error")
+		}
+
+		return []Product{{Name: fmt.Sprint(page)}}, 4, nil
+	})
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,245 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/alexlast/stock-notifier/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	proxyRotationRequest  = "request"
+	proxyRotationRetailer = "retailer"
+)
+
+// proxyKey is the request context key
+// holding the proxy chosen for a request
+type proxyKey struct{}
+
+// proxy tracks the health of a single proxy
+type proxy struct {
+	url         *url.URL
+	label       string
+	failures    int
+	until       time.Time
+	quarantined bool
+}
+
+// ProxyPool rotates requests through a pool of HTTP, HTTPS and SOCKS5
+// proxies, either per request or pinned per retailer host. Proxies
+// failing repeatedly are quarantined until the cooldown passes
+type ProxyPool struct {
+	mu         sync.Mutex
+	proxies    []*proxy
+	rotation   string
+	threshold  int
+	quarantine time.Duration
+	next       int
+	pinned     map[string]*proxy
+	now        func() time.Time
+}
+
+// proxyTransport is a round tripper recording
+// the outcome of each request against its proxy
+type proxyTransport struct {
+	pool *ProxyPool
+	base *http.Transport
+}
+
+// NewProxyPool builds the proxy pool from the config,
+// no pool is returned when no proxies are configured
+func NewProxyPool(config *Config) (*ProxyPool, error) {
+	if len(config.Proxies) == 0 {
+		return nil, nil
+	}
+
+	switch config.ProxyRotation {
+	case proxyRotationRequest, proxyRotationRetailer:
+	default:
+		return nil, fmt.Errorf("Unknown proxy rotation %s", config.ProxyRotation)
+	}
+
+	p := &ProxyPool{
+		rotation:   config.ProxyRotation,
+		threshold:  config.ProxyFailures,
+		quarantine: time.Duration(config.ProxyQuarantine) * time.Second,
+		pinned:     map[string]*proxy{},
+		now:        time.Now,
+	}
+
+	for _, raw := range config.Proxies {
+		u, err := url.Parse(raw)
+
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("Invalid proxy URL %s", redactProxy(raw))
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("Unsupported proxy scheme %s, expected http, https or socks5", u.Scheme)
+		}
+
+		// Never expose credentials in metrics
+		p.proxies = append(p.proxies, &proxy{url: u, label: u.Scheme + "://" + u.Host})
+	}
+
+	return p, nil
+}
+
+// Client returns an HTTP client sending every
+// request through the pool
+func (p *ProxyPool) Client(timeout time.Duration) *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	// Use the proxy picked for the request
+	base.Proxy = func(request *http.Request) (*url.URL, error) {
+		if px, ok := request.Context().Value(proxyKey{}).(*proxy); ok {
+			return px.url, nil
+		}
+
+		return nil, nil
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &proxyTransport{pool: p, base: base},
+	}
+}
+
+// RoundTrip sends the request through the next proxy
+func (t *proxyTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	px := t.pool.pick(request.URL.Hostname())
+	request = request.WithContext(context.WithValue(request.Context(), proxyKey{}, px))
+
+	response, err := t.base.RoundTrip(request)
+
+	// Cancelled requests say nothing about the proxy
+	if request.Context().Err() == nil {
+		t.pool.record(px, response, err)
+	}
+
+	return response, err
+}
+
+// pick returns the proxy for a request to the host, healthy
+// proxies are preferred and when every proxy is quarantined
+// the one released soonest is used
+func (p *ProxyPool) pick(host string) *proxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.release(now)
+
+	if p.rotation == proxyRotationRetailer {
+		if px, ok := p.pinned[host]; ok && !px.until.After(now) {
+			return px
+		}
+	}
+
+	var picked *proxy
+
+	for i := 0; i < len(p.proxies); i++ {
+		px := p.proxies[(p.next+i)%len(p.proxies)]
+
+		if !px.until.After(now) {
+			picked = px
+			p.next = (p.next + i + 1) % len(p.proxies)
+			break
+		}
+	}
+
+	if picked == nil {
+		picked = p.proxies[0]
+
+		for _, px := range p.proxies[1:] {
+			if px.until.Before(picked.until) {
+				picked = px
+			}
+		}
+	}
+
+	if p.rotation == proxyRotationRetailer {
+		p.pinned[host] = picked
+	}
+
+	return picked
+}
+
+// record updates the proxy health, transport errors and blocked
+// responses count as failures and quarantine the proxy once
+// the threshold of consecutive failures is reached
+func (p *ProxyPool) record(px *proxy, response *http.Response, err error) {
+	failed := err != nil || blockedStatus(response.StatusCode)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !failed {
+		px.failures = 0
+
+		metrics.ProxyRequests.With(
+			prometheus.Labels{"proxy": px.label, "result": "success"}).Inc()
+
+		return
+	}
+
+	px.failures++
+
+	metrics.ProxyRequests.With(
+		prometheus.Labels{"proxy": px.label, "result": "failure"}).Inc()
+
+	if p.threshold > 0 && px.failures >= p.threshold {
+		log.Warnf("Quarantining proxy %s for %s after %d failures", px.label, p.quarantine, px.failures)
+
+		px.failures = 0
+		px.until = p.now().Add(p.quarantine)
+		px.quarantined = true
+
+		metrics.ProxyQuarantined.With(
+			prometheus.Labels{"proxy": px.label}).Set(1)
+	}
+}
+
+// release returns proxies whose quarantine has
+// expired to the pool, the lock must be held
+func (p *ProxyPool) release(now time.Time) {
+	for _, px := range p.proxies {
+		if !px.quarantined || px.until.After(now) {
+			continue
+		}
+
+		log.Infof("Releasing proxy %s from quarantine", px.label)
+
+		px.quarantined = false
+
+		metrics.ProxyQuarantined.With(
+			prometheus.Labels{"proxy": px.label}).Set(0)
+	}
+}
+
+// blockedStatus checks whether a status code suggests
+// the retailer or proxy has blocked the request
+func blockedStatus(code int) bool {
+	return code == http.StatusForbidden || code == http.StatusProxyAuthRequired || code == http.StatusTooManyRequests
+}
+
+// redactProxy removes any credentials from a proxy URL
+func redactProxy(raw string) string {
+	u, err := url.Parse(raw)
+
+	if err != nil {
+		return "<redacted>"
+	}
+
+	u.User = nil
+
+	return u.String()
+}
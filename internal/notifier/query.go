@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StringList is a list of strings that can be
+// decoded from either a JSON string or array
+type StringList []string
+
+// UnmarshalJSON decodes a string or array of strings
+func (s *StringList) UnmarshalJSON(raw []byte) error {
+	var single string
+
+	if err := json.Unmarshal(raw, &single); err == nil {
+		*s = StringList{single}
+		return nil
+	}
+
+	var list []string
+
+	err := json.Unmarshal(raw, &list)
+
+	if err != nil {
+		return fmt.Errorf("Expected a string or list of strings, error: %v", err)
+	}
+
+	*s = list
+
+	return nil
+}
+
+// SearchQueries returns the queries sent to the retailer, a
+// retailer override takes precedence over the filter queries
+// which default to the filter term
+func (f Filter) SearchQueries(retailer string) []string {
+	for name, queries := range f.RetailerQueries {
+		if strings.EqualFold(name, retailer) && len(queries) > 0 {
+			return queries
+		}
+	}
+
+	if len(f.Query) > 0 {
+		return f.Query
+	}
+
+	return []string{f.Term}
+}
+
+// MatchTerm returns the term product names
+// must contain, defaulting to the filter term
+func (f Filter) MatchTerm() string {
+	if f.Match != "" {
+		return f.Match
+	}
+
+	return f.Term
+}
+
+// fetchQueries fetches every search query for the filter from
+// the retailer and merges the results, any failing query fails
+// the poll so partial results aren't treated as sold out
+func (c *Context) fetchQueries(ctx context.Context, retailer Retailer, filter Filter) (Response, error) {
+	var lists [][]Product
+
+	for _, query := range filter.SearchQueries(retailer.Name()) {
+		// Retailers search for the filter term
+		search := filter
+		search.Term = query
+
+		response, err := retailer.Fetch(ctx, c, search)
+
+		if err != nil {
+			return Response{}, err
+		}
+
+		lists = append(lists, response.Matches)
+	}
+
+	return Response{Matches: mergeProducts(lists...)}, nil
+}
+
+// mergeProducts merges product lists removing duplicates,
+// products are identified by URL, SKU or name
+func mergeProducts(lists ...[]Product) []Product {
+	var merged []Product
+
+	seen := map[string]bool{}
+
+	for _, products := range lists {
+		for _, product := range products {
+			key := product.key()
+
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			merged = append(merged, product)
+		}
+	}
+
+	return merged
+}
+
+// key returns the most specific
+// identifier for the product
+func (p *Product) key() string {
+	switch {
+	case p.URL != "":
+		return "url:" + p.URL
+	case p.SKU != "":
+		return "sku:" + p.SKU
+	}
+
+	return "name:" + p.Name
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSearchQueries tests decoding queries and
+// selecting queries for a retailer
+func TestSearchQueries(t *testing.T) {
+	filter := new(FilterDecoder)
+	err := filter.Decode(`[
+		{"term": "PS5", "interval": 60},
+		{"query": "PS5", "interval": 60, "match": "PlayStation 5"},
+		{"query": ["PS5", "PlayStation 5"], "interval": 60, "match": "PlayStation 5", "retailerQueries": {"argos.co.uk": "Sony PS5"}}
+	]`)
+	assert.Nil(t, err)
+
+	// The term is used for everything by default
+	term := (*filter)[0]
+	assert.Equal(t, []string{"PS5"}, term.SearchQueries("Argos.co.uk"))
+	assert.Equal(t, "PS5", term.MatchTerm())
+
+	// Separate query and match
+	single := (*filter)[1]
+	assert.Equal(t, "PlayStation 5", single.Term)
+	assert.Equal(t, []string{"PS5"}, single.SearchQueries("Argos.co.uk"))
+	assert.True(t, single.Matches("Sony PlayStation 5 Console"))
+
+	// Multiple queries with a retailer override
+	multiple := (*filter)[2]
+	assert.Equal(t, []string{"PS5", "PlayStation 5"}, multiple.SearchQueries("Very.co.uk"))
+	assert.Equal(t, []string{"Sony PS5"}, multiple.SearchQueries("Argos.co.uk"))
+
+	// Invalid queries
+	err = filter.Decode(`[{"query": 5}]`)
+	assert.NotNil(t, err)
+}
+
+// TestFetchQueries ensures results for every
+// query are merged and deduplicated
+func TestFetchQueries(t *testing.T) {
+	var searched []string
+
+	retailer := &retailerFunc{
+		name: "test",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			searched = append(searched, filter.Term)
+
+			if filter.Term == "fail" {
+				return Response{}, errors.New("Some fetch error")
+			}
+
+			return Response{Matches: []Product{
+				{Name: "PlayStation 5", URL: "https://example.com/ps5"},
+				{Name: filter.Term},
+			}}, nil
+		},
+	}
+
+	c := GetTestContext()
+	response, err := c.fetchQueries(context.Background(), retailer, Filter{Term: "PS5", Query: StringList{"PS5", "PlayStation 5"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"PS5", "PlayStation 5"}, searched)
+	assert.Len(t, response.Matches, 3)
+
+	// Any failing query fails the fetch
+	_, err = c.fetchQueries(context.Background(), retailer, Filter{Term: "PS5", Query: StringList{"PS5", "fail"}})
+	assert.NotNil(t, err)
+}
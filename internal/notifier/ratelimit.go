@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit defines the token bucket rate in requests
+// per second and the burst allowed for a host
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// RateLimitDecoder is a type used for an envconfig custom
+// decoder, limits are keyed by retailer name
+type RateLimitDecoder map[string]RateLimit
+
+// tokenBucket is a token bucket refilled at rate
+// tokens per second up to burst tokens
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// HostLimiter rate limits requests per host, buckets are shared
+// by every job so concurrent polls of a retailer share its limit
+type HostLimiter struct {
+	mu       sync.Mutex
+	fallback RateLimit
+	limits   map[string]RateLimit
+	buckets  map[string]*tokenBucket
+}
+
+// Decode is a custom decoder for rate limits
+func (r *RateLimitDecoder) Decode(value string) error {
+	limits := map[string]RateLimit{}
+
+	err := json.Unmarshal([]byte(value), &limits)
+
+	if err != nil {
+		return fmt.Errorf("Invalid rate limits JSON, error: %v", err)
+	}
+
+	*r = limits
+
+	return nil
+}
+
+// NewHostLimiter builds a limiter from the config, retailers
+// without their own limit use the default rate limit
+func NewHostLimiter(config *Config) *HostLimiter {
+	h := &HostLimiter{
+		fallback: RateLimit{Rate: config.RateLimit, Burst: config.RateBurst},
+		limits:   map[string]RateLimit{},
+		buckets:  map[string]*tokenBucket{},
+	}
+
+	for name, limit := range config.RetailerRateLimits {
+		h.limits[strings.ToLower(name)] = limit
+	}
+
+	return h
+}
+
+// Wait blocks until a request to the host of the URL is
+// allowed by its rate limit or ctx is cancelled
+func (h *HostLimiter) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return nil
+	}
+
+	return sleepContext(ctx, h.bucket(strings.ToLower(u.Hostname())).reserve(time.Now()))
+}
+
+// bucket returns the bucket for the host
+// creating it on first use
+func (h *HostLimiter) bucket(host string) *tokenBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, ok := h.buckets[host]
+
+	if !ok {
+		b = newTokenBucket(h.limitFor(host))
+		h.buckets[host] = b
+	}
+
+	return b
+}
+
+// limitFor returns the limit of the retailer serving the host,
+// www.scan.co.uk uses the limit configured for Scan.co.uk and
+// the longest matching name wins
+func (h *HostLimiter) limitFor(host string) RateLimit {
+	limit, matched := h.fallback, ""
+
+	for name, l := range h.limits {
+		if (host == name || strings.HasSuffix(host, "."+name)) && len(name) > len(matched) {
+			limit, matched = l, name
+		}
+	}
+
+	return limit
+}
+
+// newTokenBucket returns a full bucket for the limit
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(limit.Burst)
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+// reserve takes a token and returns how long to wait before it
+// can be used, tokens go negative so waiting requests queue up
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	// A zero rate disables limiting
+	if b.rate <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenBucket ensures the burst is allowed
+// and further requests wait for tokens
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(RateLimit{Rate: 2, Burst: 2})
+
+	assert.Equal(t, time.Duration(0), bucket.reserve(now))
+	assert.Equal(t, time.Duration(0), bucket.reserve(now))
+
+	// Waiting requests queue behind each other
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve(now))
+	assert.Equal(t, time.Second, bucket.reserve(now))
+
+	// Tokens refill over time
+	assert.Equal(t, time.Duration(0), bucket.reserve(now.Add(3*time.Second)))
+
+	// A zero rate is unlimited
+	unlimited := newTokenBucket(RateLimit{})
+	assert.Equal(t, time.Duration(0), unlimited.reserve(now))
+	assert.Equal(t, time.Duration(0), unlimited.reserve(now))
+}
+
+// TestHostLimiter ensures retailer limits apply
+// to their hosts and buckets are shared
+func TestHostLimiter(t *testing.T) {
+	limits := new(RateLimitDecoder)
+	err := limits.Decode(`{"Scan.co.uk": {"rate": 0.5, "burst": 1}}`)
+	assert.Nil(t, err)
+
+	limiter := NewHostLimiter(&Config{RateLimit: 1, RateBurst: 2, RetailerRateLimits: *limits})
+
+	assert.Equal(t, RateLimit{Rate: 0.5, Burst: 1}, limiter.limitFor("www.scan.co.uk"))
+	assert.Equal(t, RateLimit{Rate: 0.5, Burst: 1}, limiter.limitFor("scan.co.uk"))
+	assert.Equal(t, RateLimit{Rate: 1, Burst: 2}, limiter.limitFor("www.notscan.co.uk"))
+	assert.Equal(t, limiter.bucket("www.scan.co.uk"), limiter.bucket("www.scan.co.uk"))
+	assert.NotEqual(t, limiter.bucket("www.scan.co.uk"), limiter.bucket("www.ebuyer.com"))
+
+	// Requests over the burst wait
+	start := time.Now()
+	fast := NewHostLimiter(&Config{RateLimit: 50, RateBurst: 1})
+
+	for i := 0; i < 3; i++ {
+		fast.Wait(context.Background(), "https://www.example.com/search?page=1")
+	}
+
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+
+	// Invalid limits
+	err = limits.Decode(`{"Scan.co.uk": 1}`)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	redisKeyPrefix   = "stock-notifier:"
+	redisMaxIdle     = 3
+	redisIdleTimeout = 240 * time.Second
+)
+
+// redisCache is a cache stored in Redis, entries
+// are expired by Redis once the ttl has passed
+type redisCache struct {
+	pool *redis.Pool
+}
+
+// NewRedisCache returns a new cache backed by
+// the Redis server at the URL
+func NewRedisCache(url string) (Cache, error) {
+	pool := &redis.Pool{
+		MaxIdle:     redisMaxIdle,
+		IdleTimeout: redisIdleTimeout,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(url)
+		},
+	}
+
+	// Ensure we can reach the server
+	conn := pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("PING")
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to connect to redis, error: %v", err)
+	}
+
+	return &redisCache{pool: pool}, nil
+}
+
+// Close closes the connection pool
+func (r *redisCache) Close() error {
+	return r.pool.Close()
+}
+
+// Get returns when the key was last notified
+func (r *redisCache) Get(key string) (time.Time, bool, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	sent, err := redis.Int64(conn.Do("GET", redisKeyPrefix+key))
+
+	// The key doesn't exist or has expired
+	if err == redis.ErrNil {
+		return time.Time{}, false, nil
+	}
+
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("Unable to get %s from redis, error: %v", key, err)
+	}
+
+	return time.Unix(0, sent), true, nil
+}
+
+// Set records when the key was notified
+func (r *redisCache) Set(key string, sent time.Time, ttl time.Duration) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	args := redis.Args{redisKeyPrefix + key, sent.UnixNano()}
+
+	// Let redis expire the entry
+	if ttl > 0 {
+		args = args.Add("PX", ttl.Milliseconds())
+	}
+
+	_, err := conn.Do("SET", args...)
+
+	if err != nil {
+		return fmt.Errorf("Unable to set %s in redis, error: %v", key, err)
+	}
+
+	return nil
+}
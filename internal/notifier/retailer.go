@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Retailer defines the interface every
+// supported retailer must implement
+type Retailer interface {
+	// Name returns the display name of the retailer
+	Name() string
+	// Fetch returns all products found by the retailer
+	// for the filter, stopping when ctx is cancelled
+	Fetch(ctx context.Context, c *Context, filter Filter) (Response, error)
+}
+
+// retailerFunc is a helper for building
+// a retailer from a name and fetch function
+type retailerFunc struct {
+	name  string
+	fetch func(ctx context.Context, c *Context, filter Filter) (Response, error)
+}
+
+// registry holds all retailers that will
+// be polled, keyed by lower case name
+var registry = struct {
+	sync.RWMutex
+	retailers map[string]Retailer
+}{
+	retailers: map[string]Retailer{},
+}
+
+// Name returns the display name of the retailer
+func (r *retailerFunc) Name() string {
+	return r.name
+}
+
+// Fetch calls the underlying fetch function
+func (r *retailerFunc) Fetch(ctx context.Context, c *Context, filter Filter) (Response, error) {
+	return r.fetch(ctx, c, filter)
+}
+
+// RegisterRetailer adds a retailer to the registry, a
+// retailer with the same name will be replaced
+func RegisterRetailer(r Retailer) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.retailers[strings.ToLower(r.Name())] = r
+}
+
+// DeregisterRetailer removes a retailer from the
+// registry so it will no longer be polled
+func DeregisterRetailer(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	delete(registry.retailers, strings.ToLower(name))
+}
+
+// GetRetailer returns a registered retailer
+// by name, ignoring case
+func GetRetailer(name string) (Retailer, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	r, ok := registry.retailers[strings.ToLower(name)]
+
+	return r, ok
+}
+
+// Retailers returns all registered retailers
+// sorted by name
+func Retailers() []Retailer {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var retailers []Retailer
+
+	for _, r := range registry.retailers {
+		retailers = append(retailers, r)
+	}
+
+	// Sort so polling order is predictable
+	sort.Slice(retailers, func(i, j int) bool {
+		return retailers[i].Name() < retailers[j].Name()
+	})
+
+	return retailers
+}
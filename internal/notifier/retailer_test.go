@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetailers ensures all built in retailers
+// are registered and returned in order
+func TestRetailers(t *testing.T) {
+	var names []string
+
+	for _, r := range Retailers() {
+		names = append(names, r.Name())
+	}
+
+	assert.Equal(t, []string{
+		"Argos.co.uk",
+		"Currys.co.uk",
+		"Ebuyer.com",
+		"Novatech.co.uk",
+		"Overclockers.co.uk",
+		"Scan.co.uk",
+		"Very.co.uk",
+	}, names)
+}
+
+// TestRegisterRetailer tests registering
+// and deregistering a retailer
+func TestRegisterRetailer(t *testing.T) {
+	RegisterRetailer(&retailerFunc{
+		name: "test",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			return Response{Matches: []Product{{Name: filter.Term}}}, nil
+		},
+	})
+
+	// Fetch from the registered retailer
+	r, ok := GetRetailer("test")
+	assert.True(t, ok)
+
+	response, err := r.Fetch(context.Background(), GetTestContext(), Filter{Term: "test"})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", response.Matches[0].Name)
+
+	// Remove it again
+	DeregisterRetailer("test")
+
+	_, ok = GetRetailer("test")
+	assert.False(t, ok)
+}
@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRetryBackoff    = time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+)
+
+// get makes a rate limited GET request, retrying transient errors with
+// exponential backoff and jitter. Only 200 responses are returned and
+// the caller must close the body
+func (c *Context) get(ctx context.Context, url string) (*http.Response, error) {
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		var response *http.Response
+		var retryAfter time.Duration
+
+		response, retryAfter, err = c.tryGet(ctx, url)
+
+		if err == nil {
+			return response, nil
+		}
+
+		// Cancellation isn't worth retrying
+		if retryAfter < 0 || attempt >= c.retries() || ctx.Err() != nil {
+			return nil, err
+		}
+
+		// Give up rather than retry before the server allows
+		if retryAfter > c.maxBackoff() {
+			return nil, fmt.Errorf("%v, retry after %s exceeds the maximum backoff", err, retryAfter)
+		}
+
+		delay := c.backoff(attempt)
+
+		// Honour the server when it tells us how long to wait
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+
+		log.Debugf("%v, retrying in %s", err, delay)
+
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryGet makes a single request returning the delay requested by
+// the server for retryable errors or -1 when the error is permanent
+func (c *Context) tryGet(ctx context.Context, url string) (*http.Response, time.Duration, error) {
+	// Build a new request and assign a random user agent
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	if err != nil {
+		return nil, -1, fmt.Errorf("Unable to build request for %s, error: %v", url, err)
+	}
+
+	request.Header.Add("User-agent", getUserAgent())
+
+	err = c.waitForHost(ctx, url)
+
+	if err != nil {
+		return nil, -1, fmt.Errorf("Unable to load %s, error: %v", url, err)
+	}
+
+	response, err := c.HTTP.Do(request)
+
+	// We couldn't make the HTTP request, timeouts
+	// and connection errors are worth retrying
+	if err != nil {
+		return nil, 0, fmt.Errorf("Unable to load %s, error: %v", url, err)
+	}
+
+	if response.StatusCode == http.StatusOK {
+		return response, 0, nil
+	}
+
+	// Drain the body so the connection can be reused
+	io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+
+	err = fmt.Errorf("Unable to load %s, got status code %d", url, response.StatusCode)
+
+	if !retryableStatus(response.StatusCode) {
+		return nil, -1, err
+	}
+
+	return nil, parseRetryAfter(response.Header.Get("Retry-After"), time.Now()), err
+}
+
+// retryableStatus checks whether a status code is
+// transient, rate limits and server errors are retried
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// parseRetryAfter parses a Retry-After header given in
+// either seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// backoff returns the exponential backoff for the attempt with
+// jitter, the delay is between half and all of the backoff
+func (c *Context) backoff(attempt int) time.Duration {
+	base := defaultRetryBackoff
+
+	if c.Config != nil && c.Config.RetryBackoff > 0 {
+		base = time.Duration(c.Config.RetryBackoff) * time.Millisecond
+	}
+
+	delay := base << uint(attempt)
+
+	if delay <= 0 || delay > c.maxBackoff() {
+		delay = c.maxBackoff()
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// maxBackoff returns the longest delay between retries
+func (c *Context) maxBackoff() time.Duration {
+	if c.Config != nil && c.Config.RetryMaxBackoff > 0 {
+		return time.Duration(c.Config.RetryMaxBackoff) * time.Millisecond
+	}
+
+	return defaultRetryMaxBackoff
+}
+
+// retries returns the number of times
+// a transient error is retried
+func (c *Context) retries() int {
+	if c.Config == nil || c.Config.Retries < 0 {
+		return 0
+	}
+
+	return c.Config.Retries
+}
+
+// sleepContext sleeps for the duration returning
+// early with an error if ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetry ensures transient errors are
+// retried and permanent errors aren't
+func TestRetry(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/limited" && n == 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/blocked" && n == 1:
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/flaky" && n < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	c := GetTestContext()
+	c.HTTP = server.Client()
+	c.Config = &Config{Retries: 2, RetryBackoff: 10, RetryMaxBackoff: 2000}
+
+	// Server errors are retried
+	body, err := c.getRaw(context.Background(), server.URL+"/flaky")
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(3), requests)
+
+	// Client errors fail immediately
+	atomic.StoreInt32(&requests, 0)
+	_, err = c.getRaw(context.Background(), server.URL+"/missing")
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), requests)
+
+	// Retry-After is honoured
+	atomic.StoreInt32(&requests, 0)
+	start := time.Now()
+	_, err = c.getRaw(context.Background(), server.URL+"/limited")
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Second))
+
+	// Retry-After beyond the maximum backoff isn't retried early
+	atomic.StoreInt32(&requests, 0)
+	start = time.Now()
+	_, err = c.getRaw(context.Background(), server.URL+"/blocked")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximum backoff")
+	assert.Equal(t, int32(1), requests)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// Retries are exhausted
+	atomic.StoreInt32(&requests, 0)
+	c.Config.Retries = 1
+	_, err = c.getRaw(context.Background(), server.URL+"/flaky")
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(2), requests)
+}
+
+// TestParseRetryAfter tests parsing
+// seconds and HTTP dates
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, time.Minute, parseRetryAfter("Fri, 01 Jan 2021 12:01:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Fri, 01 Jan 2021 11:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
+
+// TestBackoff ensures the backoff grows
+// exponentially and is capped
+func TestBackoff(t *testing.T) {
+	c := GetTestContext()
+	c.Config = &Config{RetryBackoff: 100, RetryMaxBackoff: 1000}
+
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		delay := c.backoff(attempt)
+
+		assert.GreaterOrEqual(t, int64(delay), int64(max/2))
+		assert.LessOrEqual(t, int64(delay), int64(max))
+	}
+
+	// Large attempts don't overflow
+	assert.LessOrEqual(t, int64(c.backoff(100)), int64(time.Second))
+}
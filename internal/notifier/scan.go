@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -14,12 +15,22 @@ const (
 	scanSearch = "https://www.scan.co.uk/search?q=%s"
 )
 
+func init() {
+	RegisterRetailer(&retailerFunc{
+		name: "Scan.co.uk",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			return c.FetchScan(ctx, filter)
+		},
+	})
+}
+
 // FetchScan will fetch results from Scan.co.uk for the specified filter
-func (c *Context) FetchScan(filter Filter) (Response, error) {
+func (c *Context) FetchScan(ctx context.Context, filter Filter) (Response, error) {
 	response := Response{}
 
 	// Get the page contents and our goquery document
-	page, err := c.getPage(fmt.Sprintf(scanSearch, url.QueryEscape(filter.Term)))
+	pageURL := fmt.Sprintf(scanSearch, url.QueryEscape(filter.Term))
+	page, err := c.getPage(ctx, pageURL)
 
 	if err != nil {
 		return response, err
@@ -38,7 +49,10 @@ func (c *Context) FetchScan(filter Filter) (Response, error) {
 
 			// Build our product
 			product := Product{
-				Name: data.Find("span.description").Text(),
+				Name:  data.Find("span.description").Text(),
+				URL:   resolveURL(pageURL, data.Find("span.description a").AttrOr("href", "")),
+				SKU:   data.AttrOr("data-wpid", ""),
+				Image: imageSource(pageURL, data.Find("div.image")),
 			}
 
 			// Get the product price
@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexlast/stock-notifier/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// job is a function run by the scheduler
+type job struct {
+	name     string
+	label    string
+	schedule Schedule
+	hours    *ActiveHours
+	run      func()
+	running  int32
+}
+
+// Scheduler runs jobs on their schedules, runs are delayed by
+// a random jitter, held outside active hours and skipped
+// while the previous run of the same job is still going
+type Scheduler struct {
+	mu     sync.Mutex
+	jobs   []*job
+	jitter time.Duration
+	now    func() time.Time
+}
+
+// NewScheduler returns a scheduler delaying
+// each run by up to jitter
+func NewScheduler(jitter time.Duration) *Scheduler {
+	return &Scheduler{
+		jitter: jitter,
+		now:    time.Now,
+	}
+}
+
+// Add adds a job to the scheduler, the label is
+// used for metrics and the name for logging
+func (s *Scheduler) Add(name, label string, schedule Schedule, hours *ActiveHours, run func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &job{
+		name:     name,
+		label:    label,
+		schedule: schedule,
+		hours:    hours,
+		run:      run,
+	})
+}
+
+// Run runs every job until ctx is cancelled, runs that
+// are in progress are left to finish on their own
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	for _, j := range jobs {
+		wg.Add(1)
+
+		go func(j *job) {
+			defer wg.Done()
+			s.runJob(ctx, j)
+		}(j)
+	}
+
+	wg.Wait()
+}
+
+// runJob waits for each scheduled run of the job
+func (s *Scheduler) runJob(ctx context.Context, j *job) {
+	next := s.now()
+
+	for {
+		next = s.next(j, next)
+
+		// Don't catch up on runs missed while suspended
+		if now := s.now(); !next.IsZero() && next.Before(now) {
+			next = s.next(j, now)
+		}
+
+		if next.IsZero() {
+			log.Warnf("Stopped scheduling %s, its schedule has no future runs", j.name)
+			return
+		}
+
+		// Jitter each run without drifting the schedule
+		delay := next.Sub(s.now()) + s.randomJitter()
+
+		if sleepContext(ctx, delay) != nil {
+			return
+		}
+
+		s.trigger(j)
+	}
+}
+
+// next returns the next run of the job after t
+// that falls inside the active hours
+func (s *Scheduler) next(j *job, t time.Time) time.Time {
+	next := j.schedule.Next(t)
+
+	if next.IsZero() || j.hours.Contains(next) {
+		return next
+	}
+
+	// Cron schedules may never fall inside the window
+	// so the search is bounded to a year of windows
+	for i := 0; i < 366; i++ {
+		start := j.hours.NextStart(next)
+
+		// Interval schedules resume when the window opens
+		if _, ok := j.schedule.(intervalSchedule); ok {
+			return start
+		}
+
+		next = j.schedule.Next(start.Add(-time.Second))
+
+		if next.IsZero() || j.hours.Contains(next) {
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// trigger starts a run of the job unless
+// the previous run is still going
+func (s *Scheduler) trigger(j *job) {
+	if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		log.Debugf("Skipping %s, the previous run is still in progress", j.name)
+
+		metrics.SkippedPolls.With(
+			prometheus.Labels{"retailer": j.label}).Inc()
+
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&j.running, 0)
+		j.run()
+	}()
+}
+
+// randomJitter returns a random delay of up to the jitter
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
+
+// Schedule returns the schedule of the filter, a cron
+// expression takes precedence over the interval
+func (f Filter) Schedule() (Schedule, error) {
+	if f.Cron != "" {
+		schedule, err := ParseSchedule(f.Cron)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid schedule for filter %s, error: %v", f.Term, err)
+		}
+
+		return schedule, nil
+	}
+
+	if f.Interval <= 0 {
+		return nil, fmt.Errorf("Filter %s requires an interval or schedule", f.Term)
+	}
+
+	return intervalSchedule{interval: time.Duration(f.Interval) * time.Second}, nil
+}
+
+// ActiveHours returns the window the filter polls in,
+// defaulting to the active hours of the config
+func (f Filter) ActiveHours(config *Config) (*ActiveHours, error) {
+	window := f.Hours
+
+	if window == "" && config != nil {
+		window = string(config.ActiveHours)
+	}
+
+	hours, err := ParseActiveHours(window)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid active hours for filter %s, error: %v", f.Term, err)
+	}
+
+	return hours, nil
+}
@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSchedulerNext ensures runs are held
+// until the active hours
+func TestSchedulerNext(t *testing.T) {
+	s := NewScheduler(0)
+	hours, _ := ParseActiveHours("07:00-23:00")
+
+	interval := &job{schedule: intervalSchedule{interval: time.Hour}, hours: hours}
+	assert.Equal(t, at(1, 12, 30), s.next(interval, at(1, 11, 30)))
+	assert.Equal(t, at(2, 7, 0), s.next(interval, at(1, 22, 30)))
+
+	cron, _ := ParseSchedule("30 * * * *")
+	hourly := &job{schedule: cron, hours: hours}
+	assert.Equal(t, at(2, 7, 30), s.next(hourly, at(1, 22, 45)))
+
+	// Schedules outside the window never run
+	never, _ := ParseSchedule("0 3 * * *")
+	assert.True(t, s.next(&job{schedule: never, hours: hours}, at(1, 12, 0)).IsZero())
+}
+
+// TestSchedulerOverlap ensures a run is skipped
+// while the previous run is in progress
+func TestSchedulerOverlap(t *testing.T) {
+	var runs int32
+
+	release := make(chan struct{})
+	s := NewScheduler(0)
+
+	j := &job{name: "test", label: "test", run: func() {
+		atomic.AddInt32(&runs, 1)
+		<-release
+	}}
+
+	s.trigger(j)
+
+	// Wait for the run to start
+	for atomic.LoadInt32(&j.running) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	s.trigger(j)
+	s.trigger(j)
+	close(release)
+
+	// The next trigger runs once the previous run finishes
+	for atomic.LoadInt32(&j.running) == 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+// TestSchedulerRun ensures jobs run on their
+// schedule until the context is cancelled
+func TestSchedulerRun(t *testing.T) {
+	var runs int32
+
+	s := NewScheduler(5 * time.Millisecond)
+	s.Add("test", "test", intervalSchedule{interval: 10 * time.Millisecond}, nil, func() {
+		atomic.AddInt32(&runs, 1)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	s.Run(ctx)
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&runs), int32(3))
+}
+
+// TestFilterSchedule tests the schedule
+// and active hours of filters
+func TestFilterSchedule(t *testing.T) {
+	schedule, err := Filter{Term: "test", Interval: 60}.Schedule()
+	assert.Nil(t, err)
+	assert.Equal(t, intervalSchedule{interval: time.Minute}, schedule)
+
+	schedule, err = Filter{Term: "test", Interval: 60, Cron: "@hourly"}.Schedule()
+	assert.Nil(t, err)
+	assert.Equal(t, at(1, 11, 0), schedule.Next(at(1, 10, 7)))
+
+	_, err = Filter{Term: "test"}.Schedule()
+	assert.NotNil(t, err)
+
+	// Filters default to the config hours
+	hours, err := Filter{Term: "test"}.ActiveHours(&Config{ActiveHours: "07:00-23:00"})
+	assert.Nil(t, err)
+	assert.False(t, hours.Contains(at(1, 3, 0)))
+
+	hours, err = Filter{Term: "test", Hours: "00:00-06:00"}.ActiveHours(&Config{ActiveHours: "07:00-23:00"})
+	assert.Nil(t, err)
+	assert.True(t, hours.Contains(at(1, 3, 0)))
+
+	// Invalid schedules are rejected when decoding
+	filter := new(FilterDecoder)
+	assert.Nil(t, filter.Decode(`[{"term": "test", "schedule": "*/5 7-23 * * *", "activeHours": "07:00-23:00"}]`))
+	assert.NotNil(t, filter.Decode(`[{"term": "test", "schedule": "every minute"}]`))
+	assert.NotNil(t, filter.Decode(`[{"term": "test", "interval": 60, "activeHours": "7-23"}]`))
+
+	// Filters must have an interval or schedule
+	assert.NotNil(t, filter.Decode(`[{"term": "test"}]`))
+
+	// The config hours are validated when loaded
+	hoursDecoder := new(HoursDecoder)
+	assert.Nil(t, hoursDecoder.Decode("07:00-23:00"))
+	assert.Equal(t, HoursDecoder("07:00-23:00"), *hoursDecoder)
+	assert.NotNil(t, hoursDecoder.Decode("7-23"))
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// pollGroup tracks running polls, polls run under their own
+// context so they can finish after scheduling stops and are
+// only cancelled when they fail to drain in time
+type pollGroup struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+}
+
+// begin registers a running poll and returns its
+// context, ok is false once shutdown has begun
+func (p *pollGroup) begin() (ctx context.Context, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, false
+	}
+
+	p.init()
+	p.wg.Add(1)
+
+	return p.ctx, true
+}
+
+// done marks a running poll as finished
+func (p *pollGroup) done() {
+	p.wg.Done()
+}
+
+// close stops new polls from starting and returns
+// the function used to cancel running polls
+func (p *pollGroup) close() context.CancelFunc {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	p.init()
+
+	return p.cancel
+}
+
+// init creates the poll context, the lock must be held
+func (p *pollGroup) init() {
+	if p.ctx == nil {
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+	}
+}
+
+// runPoll is run by the scheduler, it tracks
+// the poll so shutdown can drain it
+func (c *Context) runPoll(retailer Retailer, filter Filter) {
+	ctx, ok := c.polls.begin()
+
+	if !ok {
+		return
+	}
+
+	defer c.polls.done()
+
+	c.PollRetailer(ctx, retailer, filter)
+}
+
+// Shutdown waits for running polls to finish, cancelling them
+// if ctx expires first, and then flushes persistent state
+func (c *Context) Shutdown(ctx context.Context) error {
+	cancel := c.polls.close()
+	drained := make(chan struct{})
+
+	go func() {
+		c.polls.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Infoln("Running polls finished")
+	case <-ctx.Done():
+		log.Warnln("Timed out waiting for running polls, cancelling them")
+		cancel()
+		<-drained
+	}
+
+	cancel()
+
+	return c.flush()
+}
+
+// flush persists the price history and product state and closes
+// the cache so nothing is lost when the process exits, a
+// failure doesn't stop the rest being persisted
+func (c *Context) flush() error {
+	var errs []string
+
+	if c.History != nil {
+		err := c.History.Flush()
+
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if c.State != nil {
+		err := c.State.Flush()
+
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if closer, ok := c.Cache.(io.Closer); ok {
+		err := closer.Close()
+
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Unable to close cache, error: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
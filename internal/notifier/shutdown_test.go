@@ -0,0 +1,217 @@
+package notifier
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingRetailer returns a retailer that signals when a
+// fetch starts and blocks until released or cancelled
+func blockingRetailer(started chan struct{}, release chan struct{}, cancelled chan struct{}) Retailer {
+	return &retailerFunc{
+		name: "test-shutdown",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			close(started)
+
+			select {
+			case <-release:
+				return Response{}, nil
+			case <-ctx.Done():
+				close(cancelled)
+				return Response{}, ctx.Err()
+			}
+		},
+	}
+}
+
+// TestShutdownDrains ensures shutdown waits for
+// running polls and refuses new polls
+func TestShutdownDrains(t *testing.T) {
+	started, release, cancelled := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	c := GetTestContext()
+	c.Config = &Config{}
+
+	go c.runPoll(blockingRetailer(started, release, cancelled), Filter{Term: "test"})
+	<-started
+
+	done := make(chan error)
+
+	go func() {
+		done <- c.Shutdown(context.Background())
+	}()
+
+	// Shutdown waits for the running poll
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before the poll finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Nil(t, <-done)
+
+	// New polls aren't started
+	_, ok := c.polls.begin()
+	assert.False(t, ok)
+}
+
+// TestShutdownTimeout ensures running polls are
+// cancelled when they don't drain in time
+func TestShutdownTimeout(t *testing.T) {
+	started, release, cancelled := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	c := GetTestContext()
+	c.Config = &Config{}
+
+	go c.runPoll(blockingRetailer(started, release, cancelled), Filter{Term: "test"})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Nil(t, c.Shutdown(ctx))
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("Running poll wasn't cancelled")
+	}
+}
+
+// TestShutdownFlush ensures persistent
+// state is written on shutdown
+func TestShutdownFlush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shutdown")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := GetTestContext()
+	c.Cache, err = NewFileCache(filepath.Join(dir, "cache.json"))
+	assert.Nil(t, err)
+	c.History, err = NewPriceHistory(filepath.Join(dir, "history.json"))
+	assert.Nil(t, err)
+	c.State, err = NewStateTracker(filepath.Join(dir, "state.json"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.Shutdown(context.Background()))
+
+	_, err = os.Stat(filepath.Join(dir, "cache.json"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "history.json"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "state.json"))
+	assert.Nil(t, err)
+}
+
+// TestShutdownFlushErrors ensures a failed flush
+// doesn't stop the rest being persisted
+func TestShutdownFlushErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shutdown")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := GetTestContext()
+	c.Cache, err = NewFileCache(filepath.Join(dir, "cache.json"))
+	assert.Nil(t, err)
+	c.History, err = NewPriceHistory(filepath.Join(dir, "history.json"))
+	assert.Nil(t, err)
+	c.State, err = NewStateTracker(filepath.Join(dir, "state.json"))
+	assert.Nil(t, err)
+
+	// Neither file can be written to a missing directory
+	c.History.path = filepath.Join(dir, "missing", "history.json")
+	c.State.path = filepath.Join(dir, "missing", "state.json")
+
+	err = c.Shutdown(context.Background())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "history")
+	assert.Contains(t, err.Error(), "state")
+
+	_, err = os.Stat(filepath.Join(dir, "cache.json"))
+	assert.Nil(t, err)
+}
+
+// TestStartStops ensures polling stops
+// when the context is cancelled
+func TestStartStops(t *testing.T) {
+	retailer := &retailerFunc{
+		name: "test-start",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			return Response{}, nil
+		},
+	}
+
+	RegisterRetailer(retailer)
+	defer DeregisterRetailer(retailer.Name())
+
+	c := GetTestContext()
+	c.Config = &Config{
+		AlertMode: alertModeTTL,
+		Filters:   FilterDecoder{{Term: "test", Interval: 3600, Retailers: []string{"test-start"}}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		assert.Nil(t, c.Start(ctx))
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start didn't return after cancellation")
+	}
+}
+
+// TestStartInvalid ensures polling doesn't start
+// with invalid filters or nothing to poll
+func TestStartInvalid(t *testing.T) {
+	c := GetTestContext()
+	c.Config = &Config{Filters: FilterDecoder{{Term: "test"}}}
+
+	assert.NotNil(t, c.Start(context.Background()))
+
+	c.Config.Filters = FilterDecoder{{Term: "test", Interval: 60, Retailers: []string{"missing"}}}
+	assert.NotNil(t, c.Start(context.Background()))
+}
+
+// TestGetCancelled ensures in flight
+// requests are cancelled
+func TestGetCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	c := GetTestContext()
+	c.HTTP = server.Client()
+	c.Config = &Config{Retries: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.getRaw(ctx, server.URL)
+
+	assert.NotNil(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
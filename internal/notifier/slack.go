@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	slackMaxBlocks = 50
+	slackMaxHeader = 150
+)
+
+// slackChannel sends alerts to a Slack webhook
+type slackChannel struct {
+	url string
+}
+
+func init() {
+	RegisterChannel("slack", func(n Notify) Channel {
+		if n.Slack == nil {
+			return nil
+		}
+
+		return &slackChannel{url: *n.Slack}
+	})
+}
+
+// slackText defines the structure
+// of a Slack text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackAccessory defines the structure
+// of an image accessory on a section
+type slackAccessory struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+// slackBlock defines the structure
+// of a Slack layout block
+type slackBlock struct {
+	Type      string          `json:"type"`
+	Text      *slackText      `json:"text,omitempty"`
+	Accessory *slackAccessory `json:"accessory,omitempty"`
+}
+
+// SlackMessage defines the structure of
+// the body sent to a Slack webhook
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// BuildSlack returns the Slack webhook messages, products are
+// split across messages to stay within the Slack block limit
+func BuildSlack(alert Alert) []*SlackMessage {
+	var messages []*SlackMessage
+
+	title := alert.Title()
+	header := title
+
+	// Slack rejects headers longer than the limit
+	if runes := []rune(header); len(runes) > slackMaxHeader {
+		header = string(runes[:slackMaxHeader-1]) + "…"
+	}
+
+	// newMessage starts a message with the title header
+	newMessage := func() *SlackMessage {
+		message := &SlackMessage{
+			Text: title,
+			Blocks: []slackBlock{
+				{
+					Type: "header",
+					Text: &slackText{Type: "plain_text", Text: header},
+				},
+			},
+		}
+
+		messages = append(messages, message)
+
+		return message
+	}
+
+	message := newMessage()
+
+	for _, product := range alert.Products {
+		// Start a new message when full
+		if len(message.Blocks) == slackMaxBlocks {
+			message = newMessage()
+		}
+
+		// Link the product name when we have a URL
+		name := fmt.Sprintf("*%s*", product.Name)
+
+		if product.URL != "" {
+			name = fmt.Sprintf("*<%s|%s>*", product.URL, product.Name)
+		}
+
+		block := slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("%s\nPrice: "+priceFormat+"\nRetailer: %s\nFilter: %s", name, product.Price, alert.Retailer, alert.Term),
+			},
+		}
+
+		if product.Image != "" {
+			block.Accessory = &slackAccessory{
+				Type:     "image",
+				ImageURL: product.Image,
+				AltText:  product.Name,
+			}
+		}
+
+		message.Blocks = append(message.Blocks, block)
+	}
+
+	// Alerts without products are sent as text
+	if len(alert.Products) == 0 && alert.Message != "" {
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "plain_text", Text: alert.Message},
+		})
+	}
+
+	return messages
+}
+
+// SendSlack will send the messages to a Slack webhook
+func (c *Context) SendSlack(ctx context.Context, url string, messages []*SlackMessage) error {
+	for _, message := range messages {
+		body, err := json.Marshal(message)
+
+		if err != nil {
+			return fmt.Errorf("Unable to marshal Slack message, error: %v", err)
+		}
+
+		err = c.postJSON(ctx, url, body, nil)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Name returns the name of the channel
+func (s *slackChannel) Name() string {
+	return "slack"
+}
+
+// Send posts the alert as Slack blocks
+func (s *slackChannel) Send(ctx context.Context, c *Context, alert Alert) error {
+	return c.SendSlack(ctx, s.url, BuildSlack(alert))
+}
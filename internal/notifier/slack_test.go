@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildSlack ensures products
+// are built into section blocks
+func TestBuildSlack(t *testing.T) {
+	messages := BuildSlack(Alert{Retailer: "Argos.co.uk", Term: "Playstation 5", Products: []Product{
+		{Name: "PS5", Price: 449.99, URL: "https://example.com/ps5", Image: "https://example.com/ps5.jpg"},
+		{Name: "PS5 Digital", Price: 359.99},
+	}})
+
+	assert.Len(t, messages, 1)
+	message := messages[0]
+
+	assert.Equal(t, "Stock found on Argos.co.uk for Playstation 5", message.Text)
+	assert.Len(t, message.Blocks, 3)
+
+	// Linked product with an image
+	assert.Equal(t, "*<https://example.com/ps5|PS5>*\nPrice: £449.99\nRetailer: Argos.co.uk\nFilter: Playstation 5", message.Blocks[1].Text.Text)
+	assert.Equal(t, "https://example.com/ps5.jpg", message.Blocks[1].Accessory.ImageURL)
+
+	// Product without a link or image
+	assert.Contains(t, message.Blocks[2].Text.Text, "*PS5 Digital*")
+	assert.Nil(t, message.Blocks[2].Accessory)
+}
+
+// TestBuildSlackBlockLimit ensures large alerts are
+// split into messages within the block limit
+func TestBuildSlackBlockLimit(t *testing.T) {
+	var products []Product
+
+	for i := 0; i < 120; i++ {
+		products = append(products, Product{Name: "PS5", Price: 449.99})
+	}
+
+	messages := BuildSlack(Alert{Retailer: "Argos.co.uk", Term: "Playstation 5", Products: products})
+	assert.Len(t, messages, 3)
+
+	sections := 0
+
+	for _, message := range messages {
+		assert.LessOrEqual(t, len(message.Blocks), slackMaxBlocks)
+		assert.Equal(t, "header", message.Blocks[0].Type)
+
+		sections += len(message.Blocks) - 1
+	}
+
+	assert.Equal(t, 120, sections)
+}
+
+// TestBuildSlackHeaderLimit ensures long titles
+// are truncated to the Slack header limit
+func TestBuildSlackHeaderLimit(t *testing.T) {
+	term := strings.Repeat("é", 200)
+	messages := BuildSlack(Alert{Retailer: "Argos.co.uk", Term: term, Products: []Product{{Name: "PS5", Price: 449.99}}})
+
+	header := messages[0].Blocks[0].Text.Text
+	assert.Equal(t, slackMaxHeader, utf8.RuneCountInString(header))
+	assert.True(t, strings.HasSuffix(header, "…"))
+	assert.Contains(t, messages[0].Text, term)
+}
+
+// TestSendSlack tests sending
+// a message to a Slack webhook
+func TestSendSlack(t *testing.T) {
+	c := GetTestContext()
+
+	// Build a mock HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		message := new(SlackMessage)
+		assert.Nil(t, json.NewDecoder(req.Body).Decode(message))
+		assert.Equal(t, "header", message.Blocks[0].Type)
+
+		rw.Write([]byte("ok"))
+	}))
+
+	// Close the server when test finishes
+	defer server.Close()
+
+	err := c.SendSlack(context.Background(), server.URL, BuildSlack(Alert{Retailer: "test", Term: "test", Products: []Product{{Name: "test"}}}))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,292 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	alertModeTTL        = "ttl"
+	alertModeTransition = "transition"
+	stateScopeFormat    = "%s:%s:%x"
+	soldOutFormat       = "The following products have sold out on %s: \n\n%s"
+	priceChangeFormat   = "The following products have changed price on %s: \n\n%s"
+	notifyErrorFormat   = "%s to notify %d"
+)
+
+// productState defines the last observed state of
+// a product, a product is available when it is
+// in stock and matches the filter price
+type productState struct {
+	Available bool      `json:"available"`
+	Product   Product   `json:"product"`
+	Seen      time.Time `json:"seen"`
+}
+
+// Transition defines a change in the
+// state of a product between polls
+type Transition struct {
+	Event         string
+	Product       Product
+	PreviousPrice float64
+}
+
+// StateTracker tracks the last observed state of every product so
+// alerts are only sent on transitions, optionally persisted to a
+// file so restarts don't alert for every product again
+type StateTracker struct {
+	sync.Mutex
+	path   string
+	scopes map[string]map[string]productState
+}
+
+// NewStateTracker returns a new state tracker persisted to the
+// file at path, an empty path keeps the state in memory
+func NewStateTracker(path string) (*StateTracker, error) {
+	s := &StateTracker{
+		path:   path,
+		scopes: map[string]map[string]productState{},
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	raw, err := ioutil.ReadFile(path)
+
+	// No state has been recorded yet
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read state file %s, error: %v", path, err)
+	}
+
+	err = json.Unmarshal(raw, &s.scopes)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal state file %s, error: %v", path, err)
+	}
+
+	return s, nil
+}
+
+// scope returns the key tracking the filter on the retailer, the
+// hash of the whole filter keeps filters sharing a term apart
+func (f Filter) scope(retailer string) string {
+	// Filters are plain config so always marshal
+	raw, _ := json.Marshal(f)
+	hash := fnv.New64a()
+	hash.Write(raw)
+
+	return fmt.Sprintf(stateScopeFormat, retailer, f.Term, hash.Sum64())
+}
+
+// Observe records the products returned by a poll of a retailer
+// for a filter and returns any transitions since the last poll,
+// products missing from the poll are considered sold out
+func (s *StateTracker) Observe(retailer string, filter Filter, products []Product) ([]Transition, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	// Track each retailer and filter separately as
+	// they will return different sets of products
+	scope := filter.scope(retailer)
+	previous := s.scopes[scope]
+	current := map[string]productState{}
+
+	var transitions []Transition
+
+	for _, product := range products {
+		available := product.InStock && product.PriceMatch(filter)
+		state, seen := previous[product.Name]
+
+		switch {
+		case available && (!seen || !state.Available):
+			transitions = append(transitions, Transition{Event: EventInStock, Product: product})
+		case available && state.Product.Price != product.Price:
+			transitions = append(transitions, Transition{Event: EventPriceChange, Product: product, PreviousPrice: state.Product.Price})
+		case !product.InStock && seen && state.Available:
+			transitions = append(transitions, Transition{Event: EventSoldOut, Product: product})
+		}
+
+		current[product.Name] = productState{
+			Available: available,
+			Product:   product,
+			Seen:      time.Now(),
+		}
+	}
+
+	// Products that were in stock but are
+	// no longer listed have sold out
+	for name, state := range previous {
+		if _, listed := current[name]; !listed && state.Available {
+			transitions = append(transitions, Transition{Event: EventSoldOut, Product: state.Product})
+		}
+	}
+
+	s.scopes[scope] = current
+
+	// Write transitions straight away, other
+	// changes are persisted on flush
+	if len(transitions) > 0 && s.path != "" {
+		return transitions, s.write()
+	}
+
+	return transitions, nil
+}
+
+// ObserveStock records the available products of a poll from a
+// degraded parser and returns those back in stock, products
+// missing or unavailable keep their last state so they
+// don't alert again once the parser recovers
+func (s *StateTracker) ObserveStock(retailer string, filter Filter, products []Product) ([]Transition, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	scope := filter.scope(retailer)
+	current := s.scopes[scope]
+
+	if current == nil {
+		current = map[string]productState{}
+		s.scopes[scope] = current
+	}
+
+	var transitions []Transition
+
+	for _, product := range products {
+		if !product.InStock || !product.PriceMatch(filter) {
+			continue
+		}
+
+		if state, seen := current[product.Name]; !seen || !state.Available {
+			transitions = append(transitions, Transition{Event: EventInStock, Product: product})
+		}
+
+		current[product.Name] = productState{
+			Available: true,
+			Product:   product,
+			Seen:      time.Now(),
+		}
+	}
+
+	if len(transitions) > 0 && s.path != "" {
+		return transitions, s.write()
+	}
+
+	return transitions, nil
+}
+
+// Flush persists the state to the file
+// if the state isn't kept in memory
+func (s *StateTracker) Flush() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	return s.write()
+}
+
+// write persists the state to the file
+func (s *StateTracker) write() error {
+	raw, err := json.Marshal(s.scopes)
+
+	if err != nil {
+		return fmt.Errorf("Unable to marshal state, error: %v", err)
+	}
+
+	err = writeFileAtomic(s.path, raw)
+
+	if err != nil {
+		return fmt.Errorf("Unable to write state file %s, error: %v", s.path, err)
+	}
+
+	return nil
+}
+
+// NotifyTransitions sends an alert for each type of transition
+// to every notify target, sold out alerts are only sent when
+// enabled in the config
+func (c *Context) NotifyTransitions(ctx context.Context, retailer string, filter Filter, transitions []Transition) error {
+	events := map[string][]Transition{}
+
+	for _, transition := range transitions {
+		events[transition.Event] = append(events[transition.Event], transition)
+	}
+
+	errs := ChannelErrors{}
+
+	for _, event := range []string{EventInStock, EventPriceChange, EventPriceDrop, EventSoldOut} {
+		if len(events[event]) == 0 || (event == EventSoldOut && !c.Config.SoldOutAlerts) {
+			continue
+		}
+
+		alert := BuildTransitionAlert(retailer, filter.Term, event, events[event])
+
+		for i, notify := range c.Config.Notify {
+			err := c.sendAlert(ctx, alert, notify)
+
+			// Keep the errors of every target
+			if err != nil {
+				errs[fmt.Sprintf(notifyErrorFormat, event, i)] = err
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// BuildTransitionAlert returns the alert for
+// transitions of the same event type
+func BuildTransitionAlert(retailer, term, event string, transitions []Transition) Alert {
+	alert := Alert{
+		Event:    event,
+		Retailer: retailer,
+		Term:     term,
+	}
+
+	var descriptions []string
+
+	for _, transition := range transitions {
+		product := transition.Product
+		alert.Products = append(alert.Products, product)
+
+		// Include the old price for price changes
+		if event == EventPriceChange || event == EventPriceDrop {
+			descriptions = append(descriptions, fmt.Sprintf("%s\n"+priceFormat+" -> "+priceFormat, product.describe(), transition.PreviousPrice, product.Price))
+			continue
+		}
+
+		descriptions = append(descriptions, product.describe())
+	}
+
+	format := smsFormat
+
+	switch event {
+	case EventSoldOut:
+		format = soldOutFormat
+	case EventPriceChange:
+		format = priceChangeFormat
+	case EventPriceDrop:
+		format = priceDropFormat
+	}
+
+	alert.Message = fmt.Sprintf(format, retailer, strings.Join(descriptions, "\n\n"))
+
+	return alert
+}
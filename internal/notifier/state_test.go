@@ -0,0 +1,200 @@
+package notifier
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestObserve tests transitions are detected
+// between polls of a retailer
+func TestObserve(t *testing.T) {
+	s, err := NewStateTracker("")
+	assert.Nil(t, err)
+
+	filter := Filter{Term: "RTX 3070", MinPrice: 400, MaxPrice: 600}
+
+	// First poll only alerts for available products
+	transitions, _ := s.Observe("test", filter, []Product{
+		{Name: "RTX 3070 A", Price: 500, InStock: true},
+		{Name: "RTX 3070 B", Price: 500},
+		{Name: "RTX 3070 C", Price: 700, InStock: true},
+	})
+
+	assert.Len(t, transitions, 1)
+	assert.Equal(t, EventInStock, transitions[0].Event)
+	assert.Equal(t, "RTX 3070 A", transitions[0].Product.Name)
+
+	// Unchanged products don't alert
+	transitions, _ = s.Observe("test", filter, []Product{
+		{Name: "RTX 3070 A", Price: 500, InStock: true},
+		{Name: "RTX 3070 B", Price: 500},
+		{Name: "RTX 3070 C", Price: 700, InStock: true},
+	})
+
+	assert.Empty(t, transitions)
+
+	// Price change, back in stock and price dropping into range
+	transitions, _ = s.Observe("test", filter, []Product{
+		{Name: "RTX 3070 A", Price: 450, InStock: true},
+		{Name: "RTX 3070 B", Price: 500, InStock: true},
+		{Name: "RTX 3070 C", Price: 550, InStock: true},
+	})
+
+	assert.Len(t, transitions, 3)
+	assert.Equal(t, EventPriceChange, transitions[0].Event)
+	assert.Equal(t, float64(500), transitions[0].PreviousPrice)
+	assert.Equal(t, EventInStock, transitions[1].Event)
+	assert.Equal(t, EventInStock, transitions[2].Event)
+
+	// Out of stock and products no longer listed sell out
+	transitions, _ = s.Observe("test", filter, []Product{
+		{Name: "RTX 3070 A", Price: 450},
+		{Name: "RTX 3070 B", Price: 500, InStock: true},
+	})
+
+	assert.Len(t, transitions, 2)
+	assert.Equal(t, EventSoldOut, transitions[0].Event)
+	assert.Equal(t, "RTX 3070 A", transitions[0].Product.Name)
+	assert.Equal(t, EventSoldOut, transitions[1].Event)
+	assert.Equal(t, "RTX 3070 C", transitions[1].Product.Name)
+
+	// Other retailers are tracked separately
+	transitions, _ = s.Observe("other", filter, []Product{
+		{Name: "RTX 3070 B", Price: 500, InStock: true},
+	})
+
+	assert.Len(t, transitions, 1)
+}
+
+// TestObserveSameTerm ensures filters sharing a
+// term are tracked separately
+func TestObserveSameTerm(t *testing.T) {
+	s, err := NewStateTracker("")
+	assert.Nil(t, err)
+
+	cheap := Filter{Term: "RTX 3070", MaxPrice: 500}
+	wide := Filter{Term: "RTX 3070", MaxPrice: 1000}
+	products := []Product{{Name: "RTX 3070", Price: 600, InStock: true}}
+
+	assert.NotEqual(t, cheap.scope("test"), wide.scope("test"))
+
+	transitions, _ := s.Observe("test", cheap, products)
+	assert.Empty(t, transitions)
+
+	transitions, _ = s.Observe("test", wide, products)
+	assert.Len(t, transitions, 1)
+
+	// Each filter alerts when the price changes
+	products[0].Price = 450
+
+	transitions, _ = s.Observe("test", cheap, products)
+	assert.Len(t, transitions, 1)
+	assert.Equal(t, EventInStock, transitions[0].Event)
+
+	transitions, _ = s.Observe("test", wide, products)
+	assert.Len(t, transitions, 1)
+	assert.Equal(t, EventPriceChange, transitions[0].Event)
+}
+
+// TestStateTrackerPersist ensures state is loaded
+// on restart so products don't alert again
+func TestStateTrackerPersist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+	filter := Filter{Term: "RTX 3070", MaxPrice: 600}
+	products := []Product{{Name: "RTX 3070", Price: 500, InStock: true}}
+
+	s, err := NewStateTracker(path)
+	assert.Nil(t, err)
+
+	transitions, err := s.Observe("test", filter, products)
+	assert.Nil(t, err)
+	assert.Len(t, transitions, 1)
+
+	// Transitions are written straight away
+	s, err = NewStateTracker(path)
+	assert.Nil(t, err)
+
+	transitions, err = s.Observe("test", filter, products)
+	assert.Nil(t, err)
+	assert.Empty(t, transitions)
+	assert.Nil(t, s.Flush())
+
+	// Invalid state files are rejected
+	assert.Nil(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = NewStateTracker(path)
+	assert.NotNil(t, err)
+}
+
+// TestNotifyTransitions ensures sold out
+// alerts are only sent when enabled
+func TestNotifyTransitions(t *testing.T) {
+	c := GetTestContext()
+
+	sns := &mockSNSClient{}
+	c.SNS = sns
+	c.Config = &Config{
+		Notify: []Notify{{Phone: aws.String("+12345678")}},
+	}
+
+	transitions := []Transition{{Event: EventSoldOut, Product: Product{Name: "RTX 3070"}}}
+
+	err := c.NotifyTransitions(context.Background(), "test", Filter{Term: "RTX 3070"}, transitions)
+	assert.Nil(t, err)
+	assert.Nil(t, sns.PublishInput)
+
+	// Enable sold out alerts
+	c.Config.SoldOutAlerts = true
+
+	err = c.NotifyTransitions(context.Background(), "test", Filter{Term: "RTX 3070"}, transitions)
+	assert.Nil(t, err)
+	assert.Equal(t, "The following products have sold out on test: \n\nRTX 3070", *sns.PublishInput.Message)
+}
+
+// TestNotifyTransitionsErrors ensures the errors
+// of every notify target are returned
+func TestNotifyTransitionsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := GetTestContext()
+	c.Config = &Config{
+		Notify: NotifyDecoder{
+			{Webhook: &Webhook{URL: server.URL + "/first"}},
+			{Webhook: &Webhook{URL: server.URL + "/second"}},
+		},
+	}
+
+	transitions := []Transition{{Event: EventInStock, Product: Product{Name: "RTX 3070"}}}
+	err := c.NotifyTransitions(context.Background(), "test", Filter{Term: "RTX 3070"}, transitions)
+
+	assert.IsType(t, ChannelErrors{}, err)
+	assert.Len(t, err, 2)
+	assert.Contains(t, err.Error(), "in_stock to notify 0")
+	assert.Contains(t, err.Error(), "in_stock to notify 1")
+	assert.NotContains(t, err.Error(), "/first")
+}
+
+// TestBuildTransitionAlert tests the
+// message for price changes
+func TestBuildTransitionAlert(t *testing.T) {
+	alert := BuildTransitionAlert("test", "RTX 3070", EventPriceChange, []Transition{
+		{Event: EventPriceChange, Product: Product{Name: "RTX 3070", Price: 450, URL: "https://example.com/rtx"}, PreviousPrice: 500},
+	})
+
+	assert.Equal(t, "Price change on test for RTX 3070", alert.Title())
+	assert.Equal(t, "The following products have changed price on test: \n\nRTX 3070\nhttps://example.com/rtx\n£500.00 -> £450.00", alert.Message)
+}
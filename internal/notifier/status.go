@@ -0,0 +1,340 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	statusNotifications = 100
+	statusContentType   = "application/json"
+)
+
+// PollStatus describes the last poll
+// of a retailer for a filter
+type PollStatus struct {
+	Term     string    `json:"term"`
+	Time     time.Time `json:"time"`
+	Duration float64   `json:"duration"`
+	Error    string    `json:"error,omitempty"`
+	Parsed   int       `json:"parsed"`
+	Matched  int       `json:"matched"`
+	Problem  string    `json:"parserProblem,omitempty"`
+}
+
+// RetailerStatus describes a retailer and
+// the last poll for each filter
+type RetailerStatus struct {
+	Name     string       `json:"name"`
+	Breaker  string       `json:"breaker"`
+	Degraded bool         `json:"parserDegraded"`
+	LastPoll *PollStatus  `json:"lastPoll"`
+	Polls    []PollStatus `json:"polls"`
+}
+
+// MatchStatus describes a product currently
+// in stock and matching a filter
+type MatchStatus struct {
+	Retailer string    `json:"retailer"`
+	Term     string    `json:"term"`
+	Updated  time.Time `json:"updated"`
+	Product
+}
+
+// NotificationStatus describes a
+// notification sent to a channel
+type NotificationStatus struct {
+	Time     time.Time `json:"time"`
+	Channel  string    `json:"channel"`
+	Event    string    `json:"event,omitempty"`
+	Retailer string    `json:"retailer"`
+	Term     string    `json:"term"`
+	Products int       `json:"products"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// StatusTracker records the last poll of each retailer, the
+// products currently matching each filter and the most
+// recent notifications so they can be served by the API
+type StatusTracker struct {
+	mu            sync.Mutex
+	polls         map[string]map[string]PollStatus
+	matches       map[string][]MatchStatus
+	notifications []NotificationStatus
+}
+
+// NewStatusTracker returns an empty status tracker
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{
+		polls:   map[string]map[string]PollStatus{},
+		matches: map[string][]MatchStatus{},
+	}
+}
+
+// RecordPoll records the result of a poll of
+// the retailer for the filter
+func (s *StatusTracker) RecordPoll(retailer string, filter Filter, poll PollStatus) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.polls[retailer]; !ok {
+		s.polls[retailer] = map[string]PollStatus{}
+	}
+
+	s.polls[retailer][filter.scope(retailer)] = poll
+}
+
+// RecordMatches replaces the products matching
+// the filter on the retailer
+func (s *StatusTracker) RecordMatches(retailer string, filter Filter, products []Product) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scope := filter.scope(retailer)
+
+	if len(products) == 0 {
+		delete(s.matches, scope)
+		return
+	}
+
+	now := time.Now()
+	matches := make([]MatchStatus, 0, len(products))
+
+	for _, product := range products {
+		matches = append(matches, MatchStatus{
+			Retailer: retailer,
+			Term:     filter.Term,
+			Updated:  now,
+			Product:  product,
+		})
+	}
+
+	s.matches[scope] = matches
+}
+
+// RecordNotification records a sent notification,
+// only the most recent are kept
+func (s *StatusTracker) RecordNotification(notification NotificationStatus) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifications = append(s.notifications, notification)
+
+	if len(s.notifications) > statusNotifications {
+		s.notifications = s.notifications[len(s.notifications)-statusNotifications:]
+	}
+}
+
+// Polls returns the last polls of the
+// retailer sorted by term
+func (s *StatusTracker) Polls(retailer string) []PollStatus {
+	polls := []PollStatus{}
+
+	if s == nil {
+		return polls
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, poll := range s.polls[retailer] {
+		polls = append(polls, poll)
+	}
+
+	sort.Slice(polls, func(i, j int) bool {
+		return polls[i].Term < polls[j].Term
+	})
+
+	return polls
+}
+
+// Matches returns the products currently matching
+// filters sorted by retailer, term and name
+func (s *StatusTracker) Matches() []MatchStatus {
+	matches := []MatchStatus{}
+
+	if s == nil {
+		return matches
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, scope := range s.matches {
+		matches = append(matches, scope...)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Retailer != matches[j].Retailer {
+			return matches[i].Retailer < matches[j].Retailer
+		}
+
+		if matches[i].Term != matches[j].Term {
+			return matches[i].Term < matches[j].Term
+		}
+
+		return matches[i].Name < matches[j].Name
+	})
+
+	return matches
+}
+
+// Notifications returns the most recent
+// notifications, newest first
+func (s *StatusTracker) Notifications() []NotificationStatus {
+	notifications := []NotificationStatus{}
+
+	if s == nil {
+		return notifications
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		notifications = append(notifications, s.notifications[i])
+	}
+
+	return notifications
+}
+
+// recordPoll records a finished poll of the retailer
+// for the filter in the status tracker
+func (c *Context) recordPoll(retailer string, filter Filter, start time.Time, response Response, problem string, err error) {
+	poll := PollStatus{
+		Term:     filter.Term,
+		Time:     start,
+		Duration: time.Since(start).Seconds(),
+		Parsed:   response.Parsed,
+		Matched:  len(response.Matches),
+		Problem:  problem,
+	}
+
+	if err != nil {
+		poll.Error = err.Error()
+	}
+
+	c.Status.RecordPoll(retailer, filter, poll)
+}
+
+// RetailerStatus returns the status of every
+// registered retailer sorted by name
+func (c *Context) RetailerStatus() []RetailerStatus {
+	statuses := []RetailerStatus{}
+
+	for _, retailer := range Retailers() {
+		name := retailer.Name()
+		status := RetailerStatus{
+			Name:     name,
+			Breaker:  c.Breakers.State(name).String(),
+			Degraded: c.Health.Degraded(name),
+			Polls:    c.Status.Polls(name),
+		}
+
+		// The most recent poll of any filter
+		for i, poll := range status.Polls {
+			if status.LastPoll == nil || poll.Time.After(status.LastPoll.Time) {
+				status.LastPoll = &status.Polls[i]
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// StatusHandler returns the handler serving the JSON status API
+// describing the filters, retailers, matches, notifications
+// and price history
+func (c *Context) StatusHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("/api/filters", statusEndpoint(func(r *http.Request) interface{} {
+		if c.Config == nil || c.Config.Filters == nil {
+			return []Filter{}
+		}
+
+		return c.Config.Filters
+	}))
+
+	mux.Handle("/api/retailers", statusEndpoint(func(r *http.Request) interface{} {
+		return c.RetailerStatus()
+	}))
+
+	mux.Handle("/api/matches", statusEndpoint(func(r *http.Request) interface{} {
+		return c.Status.Matches()
+	}))
+
+	mux.Handle("/api/notifications", statusEndpoint(func(r *http.Request) interface{} {
+		return c.Status.Notifications()
+	}))
+
+	mux.Handle("/api/history", statusEndpoint(func(r *http.Request) interface{} {
+		if c.History == nil {
+			return []ProductHistory{}
+		}
+
+		query := r.URL.Query()
+
+		return c.History.Products(query.Get("retailer"), query.Get("name"))
+	}))
+
+	return mux
+}
+
+// statusEndpoint serves the value returned
+// by fn as JSON to GET requests
+func statusEndpoint(fn func(r *http.Request) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", statusContentType)
+		err := json.NewEncoder(w).Encode(fn(r))
+
+		if err != nil {
+			log.Warnf("Unable to encode status response, error: %v", err)
+		}
+	})
+}
+
+// recordNotification records an alert sent to
+// a channel in the status tracker
+func (c *Context) recordNotification(channel string, alert Alert, err error) {
+	notification := NotificationStatus{
+		Time:     time.Now(),
+		Channel:  channel,
+		Event:    alert.Event,
+		Retailer: alert.Retailer,
+		Term:     alert.Term,
+		Products: len(alert.Products),
+	}
+
+	if err != nil {
+		notification.Error = err.Error()
+	}
+
+	c.Status.RecordNotification(notification)
+}
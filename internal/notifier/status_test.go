@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getStatus requests a status API endpoint
+// and decodes the response into value
+func getStatus(t *testing.T, handler http.Handler, path string, value interface{}) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, statusContentType, recorder.Header().Get("Content-Type"))
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), value))
+}
+
+// TestStatusTracker ensures matches are replaced
+// and only recent notifications are kept
+func TestStatusTracker(t *testing.T) {
+	var s *StatusTracker
+
+	s.RecordPoll("test-status", Filter{Term: "RTX 3070"}, PollStatus{Term: "RTX 3070"})
+	assert.Empty(t, s.Polls("test-status"))
+	assert.Empty(t, s.Matches())
+	assert.Empty(t, s.Notifications())
+
+	s = NewStatusTracker()
+	filter := Filter{Term: "RTX 3070"}
+
+	s.RecordMatches("test-status", filter, []Product{{Name: "RTX 3070 Ti"}, {Name: "RTX 3070"}})
+	assert.Len(t, s.Matches(), 2)
+	assert.Equal(t, "RTX 3070", s.Matches()[0].Name)
+
+	s.RecordMatches("test-status", filter, nil)
+	assert.Empty(t, s.Matches())
+
+	for i := 0; i < statusNotifications+10; i++ {
+		s.RecordNotification(NotificationStatus{Products: i})
+	}
+
+	notifications := s.Notifications()
+	assert.Len(t, notifications, statusNotifications)
+	assert.Equal(t, statusNotifications+9, notifications[0].Products)
+}
+
+// TestStatusHandler ensures polls, matches and notifications
+// are recorded and served by the status API
+func TestStatusHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	fail := false
+
+	retailer := &retailerFunc{
+		name: "test-status",
+		fetch: func(ctx context.Context, c *Context, filter Filter) (Response, error) {
+			if fail {
+				return Response{}, errors.New("retailer unavailable")
+			}
+
+			return Response{Matches: []Product{
+				{Name: "RTX 3070", Price: 499.99, InStock: true, URL: "https://test/rtx-3070"},
+				{Name: "RTX 3070 Ti", Price: 599.99, URL: "https://test/rtx-3070-ti"},
+			}}, nil
+		},
+	}
+
+	RegisterRetailer(retailer)
+	defer DeregisterRetailer(retailer.Name())
+
+	filter := Filter{Term: "RTX 3070", MaxPrice: 1000}
+
+	c := GetTestContext()
+	c.Status = NewStatusTracker()
+	c.Config = &Config{
+		Filters: FilterDecoder{filter},
+		Notify: NotifyDecoder{
+			{Webhook: &Webhook{URL: server.URL + "/ok"}},
+			{Webhook: &Webhook{URL: server.URL + "/fail"}},
+		},
+	}
+
+	c.PollRetailer(context.Background(), retailer, filter)
+
+	handler := c.StatusHandler()
+
+	var filters []Filter
+	getStatus(t, handler, "/api/filters", &filters)
+	assert.Len(t, filters, 1)
+	assert.Equal(t, filter.Term, filters[0].Term)
+	assert.Equal(t, filter.MaxPrice, filters[0].MaxPrice)
+
+	var matches []MatchStatus
+	getStatus(t, handler, "/api/matches", &matches)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "test-status", matches[0].Retailer)
+	assert.Equal(t, "RTX 3070", matches[0].Term)
+	assert.Equal(t, "https://test/rtx-3070", matches[0].URL)
+	assert.Equal(t, 499.99, matches[0].Price)
+
+	var notifications []NotificationStatus
+	getStatus(t, handler, "/api/notifications", &notifications)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, "webhook", notifications[0].Channel)
+	assert.Equal(t, 1, notifications[0].Products)
+	assert.Contains(t, notifications[0].Error, "500")
+	assert.Empty(t, notifications[1].Error)
+
+	// Webhook URLs carry secrets so are never served
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/notifications", nil))
+	assert.NotContains(t, recorder.Body.String(), server.URL)
+	assert.NotContains(t, recorder.Body.String(), "/fail")
+
+	// Failed polls keep the last matches
+	fail = true
+	c.PollRetailer(context.Background(), retailer, filter)
+
+	var retailers []RetailerStatus
+	getStatus(t, handler, "/api/retailers", &retailers)
+
+	var status *RetailerStatus
+
+	for i := range retailers {
+		if retailers[i].Name == "test-status" {
+			status = &retailers[i]
+		}
+	}
+
+	assert.NotNil(t, status)
+	assert.Equal(t, "closed", status.Breaker)
+	assert.Len(t, status.Polls, 1)
+	assert.Equal(t, "retailer unavailable", status.LastPoll.Error)
+	assert.Equal(t, 0, status.LastPoll.Parsed)
+
+	getStatus(t, handler, "/api/matches", &matches)
+	assert.Len(t, matches, 1)
+
+	var history []ProductHistory
+	getStatus(t, handler, "/api/history?retailer=test-status", &history)
+	assert.Len(t, history, 1)
+	assert.Equal(t, "RTX 3070", history[0].Name)
+	assert.Equal(t, 499.99, history[0].Lowest.Price)
+}
+
+// TestStatusHandlerMethods ensures the status
+// API only serves GET requests
+func TestStatusHandlerMethods(t *testing.T) {
+	handler := GetTestContext().StatusHandler()
+
+	for _, path := range []string{"/api/filters", "/api/retailers", "/api/matches", "/api/notifications", "/api/history"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code, fmt.Sprintf("POST %s", path))
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
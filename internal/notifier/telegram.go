@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+const (
+	telegramAPI        = "https://api.telegram.org"
+	telegramSendFormat = "%s/bot%s/sendMessage"
+	telegramButtonText = "Open product"
+	telegramParseMode  = "HTML"
+)
+
+// telegramChannel sends alerts via a Telegram bot
+type telegramChannel struct {
+	bot *Telegram
+}
+
+func init() {
+	RegisterChannel("telegram", func(n Notify) Channel {
+		if n.Telegram == nil {
+			return nil
+		}
+
+		return &telegramChannel{bot: n.Telegram}
+	})
+}
+
+// Telegram defines the configuration
+// for a Telegram bot notification target
+type Telegram struct {
+	BotToken string `json:"botToken"`
+	ChatID   string `json:"chatId"`
+}
+
+// telegramButton defines the structure
+// of an inline keyboard button
+type telegramButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// telegramMarkup defines the structure
+// of an inline keyboard
+type telegramMarkup struct {
+	InlineKeyboard [][]telegramButton `json:"inline_keyboard"`
+}
+
+// TelegramMessage defines the structure of
+// the body sent to the Bot API sendMessage method
+type TelegramMessage struct {
+	ChatID      string          `json:"chat_id"`
+	Text        string          `json:"text"`
+	ParseMode   string          `json:"parse_mode"`
+	ReplyMarkup *telegramMarkup `json:"reply_markup,omitempty"`
+}
+
+// BuildTelegram returns a Telegram message for each product
+// so every product gets its own "Open product" button
+func BuildTelegram(chatID string, alert Alert) []*TelegramMessage {
+	var messages []*TelegramMessage
+
+	// Alerts without products are sent as text
+	if len(alert.Products) == 0 && alert.Message != "" {
+		return []*TelegramMessage{{
+			ChatID:    chatID,
+			Text:      fmt.Sprintf("%s\n%s", html.EscapeString(alert.Title()), html.EscapeString(alert.Message)),
+			ParseMode: telegramParseMode,
+		}}
+	}
+
+	for _, product := range alert.Products {
+		message := &TelegramMessage{
+			ChatID: chatID,
+			Text: fmt.Sprintf("%s\n<b>%s</b>\nPrice: "+priceFormat+"\nRetailer: %s\nFilter: %s",
+				html.EscapeString(alert.Title()), html.EscapeString(product.Name), product.Price,
+				html.EscapeString(alert.Retailer), html.EscapeString(alert.Term)),
+			ParseMode: telegramParseMode,
+		}
+
+		if product.URL != "" {
+			message.ReplyMarkup = &telegramMarkup{
+				InlineKeyboard: [][]telegramButton{
+					{{Text: telegramButtonText, URL: product.URL}},
+				},
+			}
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+// SendTelegram will send the messages via the Telegram Bot API
+func (c *Context) SendTelegram(ctx context.Context, bot *Telegram, messages []*TelegramMessage) error {
+	api := telegramAPI
+
+	// Allow the API to be overridden
+	if c.Config != nil && c.Config.TelegramURL != "" {
+		api = strings.TrimSuffix(c.Config.TelegramURL, "/")
+	}
+
+	for _, message := range messages {
+		body, err := json.Marshal(message)
+
+		if err != nil {
+			return fmt.Errorf("Unable to marshal Telegram message, error: %v", err)
+		}
+
+		err = c.postJSON(ctx, fmt.Sprintf(telegramSendFormat, api, bot.BotToken), body, nil)
+
+		if err != nil {
+			// Don't leak the bot token in logs
+			return fmt.Errorf("Unable to send Telegram message to chat %s, error: %v", bot.ChatID, strings.ReplaceAll(err.Error(), bot.BotToken, "<token>"))
+		}
+	}
+
+	return nil
+}
+
+// Name returns the name of the channel
+func (t *telegramChannel) Name() string {
+	return "telegram"
+}
+
+// Send sends the alert via the Bot API
+func (t *telegramChannel) Send(ctx context.Context, c *Context, alert Alert) error {
+	return c.SendTelegram(ctx, t.bot, BuildTelegram(t.bot.ChatID, alert))
+}
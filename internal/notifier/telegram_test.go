@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildTelegram ensures messages are escaped
+// and include an inline button when linked
+func TestBuildTelegram(t *testing.T) {
+	messages := BuildTelegram("123", Alert{Retailer: "Scan.co.uk", Term: "RTX 3070", Products: []Product{
+		{Name: "RTX_3070 *OC* <Gaming> & [Boost]", Price: 549.5, URL: "https://example.com/rtx"},
+		{Name: "RTX 3070"},
+	}})
+
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "123", messages[0].ChatID)
+	assert.Equal(t, "HTML", messages[0].ParseMode)
+	assert.Equal(t, "Stock found on Scan.co.uk for RTX 3070\n<b>RTX_3070 *OC* &lt;Gaming&gt; &amp; [Boost]</b>\nPrice: £549.50\nRetailer: Scan.co.uk\nFilter: RTX 3070", messages[0].Text)
+	assert.Equal(t, telegramButtonText, messages[0].ReplyMarkup.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "https://example.com/rtx", messages[0].ReplyMarkup.InlineKeyboard[0][0].URL)
+
+	// No link means no button
+	assert.Nil(t, messages[1].ReplyMarkup)
+}
+
+// TestSendTelegram tests sending messages
+// against a stand in Bot API
+func TestSendTelegram(t *testing.T) {
+	c := GetTestContext()
+
+	// Build a mock HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/bottoken/sendMessage", req.URL.Path)
+
+		message := new(TelegramMessage)
+		assert.Nil(t, json.NewDecoder(req.Body).Decode(message))
+		assert.Equal(t, "123", message.ChatID)
+
+		if message.Text == "Stock found on test for test\n<b>fail</b>\nPrice: £0.00\nRetailer: test\nFilter: test" {
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+
+		rw.Write([]byte(`{"ok": true}`))
+	}))
+
+	// Close the server when test finishes
+	defer server.Close()
+
+	c.Config = &Config{
+		TelegramURL: server.URL,
+	}
+
+	bot := &Telegram{BotToken: "token", ChatID: "123"}
+
+	err := c.SendTelegram(context.Background(), bot, BuildTelegram(bot.ChatID, Alert{Retailer: "test", Term: "test", Products: []Product{{Name: "test"}}}))
+	assert.Nil(t, err)
+
+	// Errors are surfaced without the token
+	err = c.SendTelegram(context.Background(), bot, BuildTelegram(bot.ChatID, Alert{Retailer: "test", Term: "test", Products: []Product{{Name: "fail"}}}))
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "/bottoken/")
+}
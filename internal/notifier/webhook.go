@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	webhookSignatureHeader = "X-Stock-Notifier-Signature"
+	webhookSignatureFormat = "sha256=%s"
+)
+
+// webhookChannel sends alerts to a webhook
+type webhookChannel struct {
+	hook *Webhook
+}
+
+func init() {
+	RegisterChannel("webhook", func(n Notify) Channel {
+		if n.Webhook == nil {
+			return nil
+		}
+
+		return &webhookChannel{hook: n.Webhook}
+	})
+}
+
+// Webhook defines the configuration
+// for a webhook notification target
+type Webhook struct {
+	URL     string            `json:"url"`
+	Secret  string            `json:"secret"`
+	Headers map[string]string `json:"headers"`
+}
+
+// WebhookPayload defines the structure
+// of the JSON body sent to a webhook
+type WebhookPayload struct {
+	Event     string    `json:"event"`
+	Retailer  string    `json:"retailer"`
+	Term      string    `json:"term"`
+	Products  []Product `json:"products"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BuildWebhook returns the webhook payload
+func BuildWebhook(alert Alert) *WebhookPayload {
+	event := alert.Event
+
+	if event == "" {
+		event = EventInStock
+	}
+
+	payload := &WebhookPayload{
+		Event:     event,
+		Retailer:  alert.Retailer,
+		Term:      alert.Term,
+		Products:  alert.Products,
+		Timestamp: time.Now().UTC(),
+	}
+
+	// Alerts without products carry their message
+	if len(alert.Products) == 0 {
+		payload.Message = alert.Message
+	}
+
+	return payload
+}
+
+// SendWebhook will POST the payload to the webhook, signing
+// the body with HMAC-SHA256 when a secret is configured
+func (c *Context) SendWebhook(ctx context.Context, hook *Webhook, payload *WebhookPayload) error {
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return fmt.Errorf("Unable to marshal webhook payload, error: %v", err)
+	}
+
+	headers := map[string]string{}
+
+	// Add any custom headers
+	for k, v := range hook.Headers {
+		headers[k] = v
+	}
+
+	// Sign the body so the receiver
+	// can verify the request
+	if hook.Secret != "" {
+		headers[webhookSignatureHeader] = fmt.Sprintf(webhookSignatureFormat, signWebhook(hook.Secret, body))
+	}
+
+	return c.postJSON(ctx, hook.URL, body, headers)
+}
+
+// signWebhook returns the hex encoded
+// HMAC-SHA256 of the body
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Name returns the name of the channel
+func (w *webhookChannel) Name() string {
+	return "webhook"
+}
+
+// Send posts the alert to the webhook
+func (w *webhookChannel) Send(ctx context.Context, c *Context, alert Alert) error {
+	return c.SendWebhook(ctx, w.hook, BuildWebhook(alert))
+}
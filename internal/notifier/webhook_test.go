@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSendWebhook tests the webhook payload,
+// signature and custom headers
+func TestSendWebhook(t *testing.T) {
+	c := GetTestContext()
+
+	// Build a mock HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Nil(t, err)
+
+		// Test request parameters
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "token", req.Header.Get("X-Token"))
+		assert.Equal(t, fmt.Sprintf(webhookSignatureFormat, signWebhook("secret", body)), req.Header.Get(webhookSignatureHeader))
+
+		// Test the payload
+		payload := new(WebhookPayload)
+		assert.Nil(t, json.Unmarshal(body, payload))
+		assert.Equal(t, EventInStock, payload.Event)
+		assert.Equal(t, "Scan.co.uk", payload.Retailer)
+		assert.Equal(t, "RTX 3070", payload.Term)
+		assert.Equal(t, "https://example.com/rtx", payload.Products[0].URL)
+		assert.False(t, payload.Timestamp.IsZero())
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+
+	// Close the server when test finishes
+	defer server.Close()
+
+	hook := &Webhook{
+		URL:     server.URL,
+		Secret:  "secret",
+		Headers: map[string]string{"X-Token": "token"},
+	}
+
+	err := c.SendWebhook(context.Background(), hook, BuildWebhook(Alert{Retailer: "Scan.co.uk", Term: "RTX 3070", Products: []Product{{Name: "RTX 3070", Price: 500, URL: "https://example.com/rtx"}}}))
+	assert.Nil(t, err)
+}
+
+// TestSendWebhookBadStatus tests the webhook
+// errors on a non 2xx response
+func TestSendWebhookBadStatus(t *testing.T) {
+	c := GetTestContext()
+
+	// Build a mock HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// No signature without a secret
+		assert.Empty(t, req.Header.Get(webhookSignatureHeader))
+
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	// Close the server when test finishes
+	defer server.Close()
+
+	err := c.SendWebhook(context.Background(), &Webhook{URL: server.URL}, BuildWebhook(Alert{Retailer: "test", Term: "test"}))
+	assert.NotNil(t, err)
+}